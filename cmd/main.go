@@ -8,7 +8,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/config"
+	"github.com/mutsaevz/team-5-ambitious/internal/events"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/policy"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 	"github.com/mutsaevz/team-5-ambitious/internal/transports"
@@ -34,33 +36,130 @@ func main() {
 		&models.Car{},
 		&models.Trip{},
 		&models.Booking{},
-		&models.Review{}); err != nil {
+		&models.Review{},
+		&models.ReviewReport{},
+		&models.ReviewReply{},
+		&models.ReviewHelpfulVote{},
+		&models.CarPhoto{},
+		&models.VerificationDocument{},
+		&models.UserBlock{},
+		&models.AuditEntry{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.DataExport{},
+		&models.AccountErasure{},
+		&models.Notification{},
+		&models.SavedSearch{},
+		&models.SearchAlert{},
+		&models.DriverRating{},
+		&models.PassengerRating{}); err != nil {
 		logger.Error("failed to migrate database", "error", err)
 		os.Exit(1)
 	}
 
 	logger.Info("migrations completed")
 
+	blobStorage := config.SetUpBlobStorage(logger)
+	cursorCodec := config.SetUpCursorCodec(logger)
+	reviewPolicy := config.SetUpReviewPolicy(logger)
+	moderationFilter := config.SetUpModerationFilter(logger)
+	ratingScoring := config.SetUpRatingScoring(logger)
+	verificationRule := config.SetUpVerificationRule(logger)
+	tokens := config.SetUpTokens(logger)
+	notificationChannels := config.SetUpNotificationChannels(logger)
+	notificationRetry := config.SetUpNotificationRetry(logger)
+
 	userRepo := repository.NewUserRepository(db, logger)
 	carRepo := repository.NewCarRepository(db, logger)
-	tripRepo := repository.NewTripRepository(db, logger)
+	tripRepo := repository.NewTripRepository(db, ratingScoring, logger)
+
+	eventBus := events.NewMemoryBus(logger)
 
 	tripStatusWorker := services.NewTripStatusWorker(
 		tripRepo,
+		eventBus,
 		logger,
 		time.Minute,
+		30*time.Minute,
 	)
 
-	tripStatusWorker.Start(ctx)
-
 	bookingRepo := repository.NewBookingRepository(db, logger)
 	reviewRepo := repository.NewReviewRepository(db, logger)
+	reviewReplyRepo := repository.NewReviewReplyRepository(db, logger)
+	driverRatingRepo := repository.NewDriverRatingRepository(db, ratingScoring, logger)
+	passengerRatingRepo := repository.NewPassengerRatingRepository(db, logger)
+
+	// старые отзывы пассажиров адресованы водителю поездки
+	if err := reviewRepo.BackfillTargets(); err != nil {
+		logger.Error("failed to backfill review targets", "error", err)
+		os.Exit(1)
+	}
+
+	// агрегаты рейтинга для водителей, чьи отзывы появились до их введения
+	if err := driverRatingRepo.Backfill(); err != nil {
+		logger.Error("failed to backfill driver ratings", "error", err)
+		os.Exit(1)
+	}
 
+	// пользователи, добавившие автомобиль до появления ролей, становятся водителями
+	if count, err := userRepo.BackfillDriverRoles(); err != nil {
+		logger.Error("failed to backfill driver roles", "error", err)
+		os.Exit(1)
+	} else if count > 0 {
+		logger.Info("driver roles assigned", "count", count)
+	}
+
+	photoRepo := repository.NewPhotoRepository(db, logger)
+	savedSearchRepo := repository.NewSavedSearchRepository(db, logger)
+	verificationRepo := repository.NewVerificationRepository(db, logger)
+	blockRepo := repository.NewBlockRepository(db, logger)
+	auditRepo := repository.NewAuditRepository(db, logger)
+	sessionRepo := repository.NewSessionRepository(db, logger)
+	accountRepo := repository.NewAccountRepository(db, logger)
+	dataExportRepo := repository.NewDataExportRepository(db, logger)
+	notificationRepo := repository.NewNotificationRepository(db, logger)
+
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, notificationChannels, notificationRetry, logger)
 	userService := services.NewUserService(userRepo, logger)
 	carService := services.NewCarService(carRepo, userRepo, logger)
-	tripService := services.NewTripService(tripRepo, userRepo, carRepo, logger)
-	bookingService := services.NewBookingService(bookingRepo, tripRepo, db, logger)
-	reviewService := services.NewReviewService(reviewRepo, tripRepo, db, logger)
+	savedSearchService := services.NewSavedSearchService(savedSearchRepo, userRepo, notificationService, db, logger)
+	tripService := services.NewTripService(tripRepo, userRepo, carRepo, savedSearchService, eventBus, verificationRule, logger)
+	bookingService := services.NewBookingService(bookingRepo, tripRepo, userRepo, blockRepo, reviewPolicy, notificationService, db, logger)
+	reviewService := services.NewReviewService(reviewRepo, reviewReplyRepo, tripRepo, bookingRepo, driverRatingRepo, passengerRatingRepo, reviewPolicy, moderationFilter, db, logger)
+	// телефоны, сохранённые до нормализации, приводятся к E.164
+	if count, err := userService.NormalizePhones(); err != nil {
+		logger.Error("failed to normalize phones", "error", err)
+		os.Exit(1)
+	} else if count > 0 {
+		logger.Info("phones normalized", "count", count)
+	}
+
+	photoService := services.NewPhotoService(photoRepo, userRepo, carRepo, blobStorage, logger)
+	verificationService := services.NewVerificationService(verificationRepo, userRepo, carRepo, blobStorage, db, logger)
+	blockService := services.NewBlockService(blockRepo, bookingRepo, tripRepo, userRepo, notificationService, db, logger)
+	auditService := services.NewAuditService(auditRepo, logger)
+	authService := services.NewAuthService(userRepo, sessionRepo, tokens, db, logger)
+	accountService := services.NewAccountService(accountRepo, dataExportRepo, userRepo, tripRepo, bookingRepo, blobStorage, notificationService, db, logger)
+
+	if count, err := authService.EnsureAdmins(config.AdminPhones()); err != nil {
+		logger.Error("failed to assign admin roles", "error", err)
+		os.Exit(1)
+	} else if count > 0 {
+		logger.Info("admin roles assigned", "count", count)
+	}
+
+	driverService := services.NewDriverService(tripRepo, bookingRepo, userRepo, reviewRepo, driverRatingRepo, logger)
+
+	// подписчики регистрируются до запуска воркера, чтобы не пропустить первые события
+	eventBus.Subscribe(events.TripStarted, "booking.expire_pending", func(_ context.Context, e events.Event) error {
+		_, err := bookingService.ExpirePending(e.TripID)
+		return err
+	})
+
+	tripStatusWorker.Start(ctx)
+	services.NewReviewRevealWorker(reviewService, logger, 10*time.Minute).Start(ctx)
+	services.NewDataExportWorker(accountService, logger, time.Minute).Start(ctx)
+	services.NewNotificationWorker(notificationService, logger, 15*time.Second).Start(ctx)
 
 	transports.RegisterRoutes(
 		r, logger,
@@ -69,6 +168,17 @@ func main() {
 		tripService,
 		bookingService,
 		reviewService,
+		photoService,
+		driverService,
+		savedSearchService,
+		verificationService,
+		blockService,
+		authService,
+		auditService,
+		accountService,
+		policy.New(tripRepo, bookingRepo, carRepo),
+		blobStorage,
+		cursorCodec,
 	)
 
 	port := os.Getenv("PORT")
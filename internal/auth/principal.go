@@ -0,0 +1,18 @@
+package auth
+
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+// Principal — пользователь, от имени которого выполняется запрос
+type Principal struct {
+	UserID    uint
+	SessionID uint
+	Role      constants.Role
+}
+
+func (p *Principal) IsStaff() bool {
+	return p.Role.IsStaff()
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.Role == constants.RoleAdmin
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/auth"
+)
+
+const (
+	defaultTokenTTL   = time.Hour
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// SetUpTokens создаёт выпуск токенов из AUTH_TOKEN_SECRET, AUTH_TOKEN_TTL_MINUTES
+// и AUTH_REFRESH_TTL_DAYS (сколько живёт сессия устройства без обновления токена).
+// Без секрета генерируется случайный ключ: после рестарта всем придётся войти заново.
+func SetUpTokens(logger *slog.Logger) *auth.Tokens {
+	secret := []byte(os.Getenv("AUTH_TOKEN_SECRET"))
+
+	if len(secret) == 0 {
+		logger.Warn("AUTH_TOKEN_SECRET is not set, using a random key")
+
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Error("Failed to generate token secret", "error", err)
+			panic(err)
+		}
+	}
+
+	ttl := envDuration(logger, "AUTH_TOKEN_TTL_MINUTES", time.Minute, defaultTokenTTL)
+	refreshTTL := envDuration(logger, "AUTH_REFRESH_TTL_DAYS", 24*time.Hour, defaultRefreshTTL)
+
+	tokens, err := auth.NewTokens(secret, ttl, refreshTTL)
+	if err != nil {
+		logger.Error("Failed to initialize tokens", "error", err)
+		panic(err)
+	}
+
+	return tokens
+}
+
+// envDuration читает целое число единиц unit из переменной окружения
+func envDuration(logger *slog.Logger, key string, unit, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		logger.Warn("invalid "+key+", using default", "value", raw)
+		return fallback
+	}
+
+	return time.Duration(n) * unit
+}
+
+// AdminPhones — телефоны через запятую из ADMIN_PHONES, которым при старте выдаётся роль администратора
+func AdminPhones() []string {
+	return splitList(os.Getenv("ADMIN_PHONES"))
+}
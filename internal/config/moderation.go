@@ -0,0 +1,39 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/moderation"
+)
+
+// SetUpModerationFilter читает списки запрещённых слов через запятую
+// из MODERATION_BANNED_WORDS_RU и MODERATION_BANNED_WORDS_EN и допустимый повтор
+// символов из MODERATION_MAX_REPEAT
+func SetUpModerationFilter(logger *slog.Logger) *moderation.Filter {
+	var maxRepeat int
+
+	if raw := os.Getenv("MODERATION_MAX_REPEAT"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			logger.Warn("invalid MODERATION_MAX_REPEAT, using default", "value", raw)
+		} else {
+			maxRepeat = n
+		}
+	}
+
+	return moderation.NewFilter(moderation.Config{
+		BannedRU:  splitList(os.Getenv("MODERATION_BANNED_WORDS_RU")),
+		BannedEN:  splitList(os.Getenv("MODERATION_BANNED_WORDS_EN")),
+		MaxRepeat: maxRepeat,
+	})
+}
+
+func splitList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/notify"
+)
+
+// SetUpNotificationChannels выбирает реализацию каждого канала по NOTIFY_SMS_DRIVER,
+// NOTIFY_EMAIL_DRIVER и NOTIFY_PUSH_DRIVER: log (по умолчанию), file или off.
+// Файловые заглушки пишут в NOTIFY_FILE_DIR/<канал>.jsonl
+func SetUpNotificationChannels(logger *slog.Logger) notify.Channels {
+	dir := os.Getenv("NOTIFY_FILE_DIR")
+	if dir == "" {
+		dir = "notifications"
+	}
+
+	return notify.Channels{
+		SMS:   notificationSender(logger, "NOTIFY_SMS_DRIVER", filepath.Join(dir, "sms.jsonl")),
+		Email: notificationSender(logger, "NOTIFY_EMAIL_DRIVER", filepath.Join(dir, "email.jsonl")),
+		Push:  notificationSender(logger, "NOTIFY_PUSH_DRIVER", filepath.Join(dir, "push.jsonl")),
+	}
+}
+
+// stubSender — заглушки умеют отправлять в любой канал
+type stubSender interface {
+	notify.SMSSender
+	notify.EmailSender
+	notify.PushSender
+}
+
+func notificationSender(logger *slog.Logger, key, path string) stubSender {
+	switch driver := strings.ToLower(os.Getenv(key)); driver {
+	case "off":
+		logger.Info("Notification channel disabled", "key", key)
+		return nil
+
+	case "file":
+		sender, err := notify.NewFileSender(path)
+		if err != nil {
+			logger.Error("Failed to initialize file notification sender", "path", path, "error", err)
+			panic(err)
+		}
+		return sender
+
+	case "", "log":
+		return notify.NewLogSender(logger)
+
+	default:
+		logger.Warn("invalid "+key+", using log", "value", driver)
+		return notify.NewLogSender(logger)
+	}
+}
+
+// SetUpNotificationRetry читает NOTIFY_MAX_ATTEMPTS и NOTIFY_RETRY_BASE_SECONDS —
+// задержку перед первой повторной попыткой, дальше она удваивается
+func SetUpNotificationRetry(logger *slog.Logger) notify.Retry {
+	retry := notify.Retry{
+		MaxAttempts: notify.DefaultMaxAttempts,
+		BaseDelay:   durationEnv(logger, "NOTIFY_RETRY_BASE_SECONDS", time.Second, notify.DefaultBaseDelay),
+		MaxDelay:    notify.DefaultMaxDelay,
+	}
+
+	if raw := os.Getenv("NOTIFY_MAX_ATTEMPTS"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			logger.Warn("invalid NOTIFY_MAX_ATTEMPTS, using default", "value", raw)
+		} else {
+			retry.MaxAttempts = n
+		}
+	}
+
+	return retry
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"os"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+)
+
+// SetUpCursorCodec создаёт подпись курсоров из CURSOR_SECRET.
+// Без секрета генерируется случайный ключ: курсоры перестанут работать после рестарта.
+func SetUpCursorCodec(logger *slog.Logger) *pagination.Codec {
+	secret := []byte(os.Getenv("CURSOR_SECRET"))
+
+	if len(secret) == 0 {
+		logger.Warn("CURSOR_SECRET is not set, using a random key")
+
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Error("Failed to generate cursor secret", "error", err)
+			panic(err)
+		}
+	}
+
+	codec, err := pagination.NewCodec(secret)
+	if err != nil {
+		logger.Error("Failed to initialize cursor codec", "error", err)
+		panic(err)
+	}
+
+	return codec
+}
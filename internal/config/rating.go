@@ -0,0 +1,43 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+// SetUpRatingScoring читает параметры ранжирующей оценки водителей:
+// RATING_PRIOR_MEAN, RATING_PRIOR_WEIGHT и RATING_HALF_LIFE_DAYS
+func SetUpRatingScoring(logger *slog.Logger) models.RatingScoring {
+	scoring := models.DefaultRatingScoring
+
+	if v, ok := floatEnv(logger, "RATING_PRIOR_MEAN"); ok && v >= 1 && v <= 5 {
+		scoring.PriorMean = v
+	}
+
+	if v, ok := floatEnv(logger, "RATING_PRIOR_WEIGHT"); ok && v > 0 {
+		scoring.PriorWeight = v
+	}
+
+	scoring.HalfLife = durationEnv(logger, "RATING_HALF_LIFE_DAYS", 24*time.Hour, scoring.HalfLife)
+
+	return scoring
+}
+
+func floatEnv(logger *slog.Logger, key string) (float64, bool) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		logger.Warn("invalid "+key+", using default", "value", raw)
+		return 0, false
+	}
+
+	return v, true
+}
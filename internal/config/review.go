@@ -0,0 +1,94 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+// SetUpReviewPolicy читает REVIEW_WINDOW_DAYS — сколько дней после поездки принимаются отзывы,
+// REVIEW_REPLY_EDIT_HOURS — сколько часов водитель может править ответ,
+// и правило общей оценки из REVIEW_OVERALL_RULE / REVIEW_CATEGORY_WEIGHTS
+func SetUpReviewPolicy(logger *slog.Logger) services.ReviewPolicy {
+	return services.ReviewPolicy{
+		Window:          durationEnv(logger, "REVIEW_WINDOW_DAYS", 24*time.Hour, services.DefaultReviewWindow),
+		ReplyEditWindow: durationEnv(logger, "REVIEW_REPLY_EDIT_HOURS", time.Hour, services.DefaultReplyEditWindow),
+		Overall:         overallRule(logger),
+	}
+}
+
+// overallRule читает режим (mean, min, weighted) и веса вида "punctuality:2,driving_safety:3"
+func overallRule(logger *slog.Logger) services.OverallRule {
+	rule := services.OverallRule{Mode: services.OverallMean}
+
+	switch mode := services.OverallMode(os.Getenv("REVIEW_OVERALL_RULE")); mode {
+	case "":
+	case services.OverallMean, services.OverallMin, services.OverallWeighted:
+		rule.Mode = mode
+	default:
+		logger.Warn("invalid REVIEW_OVERALL_RULE, using mean", "value", mode)
+	}
+
+	if rule.Mode != services.OverallWeighted {
+		return rule
+	}
+
+	rule.Weights = make(map[constants.ReviewCategory]float64, len(constants.ReviewCategories))
+
+	for _, pair := range splitList(os.Getenv("REVIEW_CATEGORY_WEIGHTS")) {
+		name, raw, _ := strings.Cut(pair, ":")
+		category := constants.ReviewCategory(strings.TrimSpace(name))
+
+		if !slices.Contains(constants.ReviewCategories, category) {
+			logger.Warn("unknown review category in REVIEW_CATEGORY_WEIGHTS", "value", pair)
+			continue
+		}
+
+		weight, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || weight < 0 {
+			logger.Warn("invalid weight in REVIEW_CATEGORY_WEIGHTS", "value", pair)
+			continue
+		}
+
+		rule.Weights[category] = weight
+	}
+
+	// категория без веса считается с весом 1, так что вывести оценку нельзя,
+	// только если все веса явно нулевые
+	positive := false
+	for _, category := range constants.ReviewCategories {
+		if w, ok := rule.Weights[category]; !ok || w > 0 {
+			positive = true
+			break
+		}
+	}
+	if !positive {
+		logger.Warn("all REVIEW_CATEGORY_WEIGHTS are zero, using mean")
+		return services.OverallRule{Mode: services.OverallMean}
+	}
+
+	return rule
+}
+
+// durationEnv читает положительное целое число единиц unit из переменной окружения
+func durationEnv(logger *slog.Logger, key string, unit, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		logger.Warn("invalid "+key+", using default", "value", raw)
+		return fallback
+	}
+
+	return time.Duration(n) * unit
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/storage"
+)
+
+// SetUpBlobStorage выбирает реализацию хранилища по STORAGE_DRIVER (local по умолчанию)
+func SetUpBlobStorage(logger *slog.Logger) storage.BlobStorage {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "s3":
+		s3, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+		if err != nil {
+			logger.Error("Failed to initialize s3 storage", "error", err)
+			panic(err)
+		}
+
+		logger.Info("Using s3 blob storage", "bucket", os.Getenv("S3_BUCKET"))
+		return s3
+
+	default:
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+
+		baseURL := strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080"
+		}
+
+		// без секрета генерируется случайный ключ: выданные ссылки перестанут работать после рестарта
+		secret := []byte(os.Getenv("STORAGE_URL_SECRET"))
+		if len(secret) == 0 {
+			logger.Warn("STORAGE_URL_SECRET is not set, using a random key")
+
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				logger.Error("Failed to generate storage url secret", "error", err)
+				panic(err)
+			}
+		}
+
+		local, err := storage.NewLocalStorage(dir, baseURL, secret)
+		if err != nil {
+			logger.Error("Failed to initialize local storage", "error", err)
+			panic(err)
+		}
+
+		logger.Info("Using local blob storage", "dir", dir)
+		return local
+	}
+}
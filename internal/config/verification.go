@@ -0,0 +1,22 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// SetUpVerificationRule читает TRIP_VERIFICATION_RULE — что должно быть проверено
+// у водителя перед публикацией поездки: off (по умолчанию), driver или driver_and_car
+func SetUpVerificationRule(logger *slog.Logger) constants.VerificationRule {
+	switch rule := constants.VerificationRule(os.Getenv("TRIP_VERIFICATION_RULE")); rule {
+	case "":
+		return constants.VerificationNotRequired
+	case constants.VerificationNotRequired, constants.VerificationDriver, constants.VerificationDriverAndCar:
+		return rule
+	default:
+		logger.Warn("invalid TRIP_VERIFICATION_RULE, verification is not required", "value", rule)
+		return constants.VerificationNotRequired
+	}
+}
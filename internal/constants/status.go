@@ -7,12 +7,204 @@ const (
 	TripPublished  TripStatus = "published"
 	TripInProgress TripStatus = "in_progress"
 	TripCompleted  TripStatus = "completed"
+	TripCancelled  TripStatus = "cancelled" // водитель удалил аккаунт до начала поездки
 )
 
 type BookingStatus string
 
 const (
-	BookingPending  = "pending"   // заявка отправлена
-	BookingApproved = "approved"  // водитель принял
-	BookingRejected = "rejected"  // водитель отклонил
+	BookingPending   = "pending"   // заявка отправлена
+	BookingApproved  = "approved"  // водитель принял
+	BookingRejected  = "rejected"  // водитель отклонил
+	BookingCancelled = "cancelled" // пассажир отменил
+	BookingNoShow    = "no_show"   // пассажир не пришёл к отправлению
+)
+
+type CarBodyType string
+
+const (
+	CarBodySedan     CarBodyType = "sedan"
+	CarBodyHatchback CarBodyType = "hatchback"
+	CarBodyWagon     CarBodyType = "wagon"
+	CarBodySUV       CarBodyType = "suv"
+	CarBodyMinivan   CarBodyType = "minivan"
+	CarBodyVan       CarBodyType = "van"
+)
+
+func (t CarBodyType) IsValid() bool {
+	switch t {
+	case CarBodySedan, CarBodyHatchback, CarBodyWagon, CarBodySUV, CarBodyMinivan, CarBodyVan:
+		return true
+	}
+	return false
+}
+
+type Gender string
+
+const (
+	GenderMale   Gender = "male"
+	GenderFemale Gender = "female"
+)
+
+func (g Gender) IsValid() bool {
+	return g == GenderMale || g == GenderFemale
+}
+
+// ReviewDirection — кто кого оценивает
+type ReviewDirection string
+
+const (
+	ReviewPassengerToDriver ReviewDirection = "passenger_to_driver"
+	ReviewDriverToPassenger ReviewDirection = "driver_to_passenger"
+)
+
+// ReviewStatus — состояние модерации отзыва
+type ReviewStatus string
+
+const (
+	ReviewPendingModeration ReviewStatus = "pending_moderation" // ждёт модератора
+	ReviewPublished         ReviewStatus = "published"
+	ReviewHidden            ReviewStatus = "hidden" // скрыт модератором
+)
+
+// ReviewCategory — категория оценки водителя в отзыве пассажира
+type ReviewCategory string
+
+const (
+	CategoryPunctuality   ReviewCategory = "punctuality"
+	CategoryDrivingSafety ReviewCategory = "driving_safety"
+	CategoryCarComfort    ReviewCategory = "car_comfort"
+	CategoryCommunication ReviewCategory = "communication"
+)
+
+// ReviewCategories — все категории в порядке вывода
+var ReviewCategories = []ReviewCategory{
+	CategoryPunctuality,
+	CategoryDrivingSafety,
+	CategoryCarComfort,
+	CategoryCommunication,
+}
+
+// ContactMethod — как пользователь предпочитает, чтобы с ним связывались
+type ContactMethod string
+
+const (
+	ContactPhone    ContactMethod = "phone"
+	ContactSMS      ContactMethod = "sms"
+	ContactWhatsApp ContactMethod = "whatsapp"
+	ContactTelegram ContactMethod = "telegram"
+	ContactInApp    ContactMethod = "in_app"
+)
+
+func (c ContactMethod) IsValid() bool {
+	switch c {
+	case ContactPhone, ContactSMS, ContactWhatsApp, ContactTelegram, ContactInApp:
+		return true
+	}
+	return false
+}
+
+// DocumentType — документ, который водитель загружает для проверки
+type DocumentType string
+
+const (
+	DocumentDriverLicense   DocumentType = "driver_license"
+	DocumentCarRegistration DocumentType = "car_registration" // СТС автомобиля
+)
+
+func (t DocumentType) IsValid() bool {
+	return t == DocumentDriverLicense || t == DocumentCarRegistration
+}
+
+// VerificationStatus — состояние проверки документа
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending" // ждёт администратора
+	VerificationApproved VerificationStatus = "approved"
+	VerificationRejected VerificationStatus = "rejected"
+)
+
+// Role — роль пользователя; от неё зависят доступные ручки
+type Role string
+
+const (
+	RolePassenger Role = "passenger"
+	RoleDriver    Role = "driver"  // есть автомобиль, может публиковать поездки
+	RoleSupport   Role = "support" // читает данные пользователей и модерирует
+	RoleAdmin     Role = "admin"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RolePassenger, RoleDriver, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// IsStaff — сотрудник сервиса: поддержка или администратор
+func (r Role) IsStaff() bool {
+	return r == RoleSupport || r == RoleAdmin
+}
+
+// SessionRevokeReason — почему сессия устройства закрыта
+type SessionRevokeReason string
+
+const (
+	SessionLogout          SessionRevokeReason = "logout"           // выход на самом устройстве
+	SessionRevoked         SessionRevokeReason = "revoked"          // закрыта из списка устройств
+	SessionLogoutAll       SessionRevokeReason = "logout_all"       // «выйти везде»
+	SessionPasswordChanged SessionRevokeReason = "password_changed" // смена или сброс пароля
+	SessionTokenReuse      SessionRevokeReason = "token_reuse"      // повторно предъявлен токен обновления
+)
+
+// ExportStatus — состояние выгрузки персональных данных
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "pending"
+	ExportProcessing ExportStatus = "processing"
+	ExportReady      ExportStatus = "ready" // архив можно скачать до ExpiresAt
+	ExportFailed     ExportStatus = "failed"
+	ExportExpired    ExportStatus = "expired" // архив удалён из хранилища
+)
+
+// VerificationRule — что должно быть проверено, прежде чем водитель сможет публиковать поездки
+type VerificationRule string
+
+const (
+	VerificationNotRequired  VerificationRule = "off"
+	VerificationDriver       VerificationRule = "driver"         // одобрено водительское удостоверение
+	VerificationDriverAndCar VerificationRule = "driver_and_car" // и удостоверение, и СТС автомобиля
+)
+
+// NotificationKind — событие, о котором уведомляем пользователя; задаёт шаблон текста
+type NotificationKind string
+
+const (
+	NotificationBookingCreated   NotificationKind = "booking_created"   // водителю: новая заявка
+	NotificationBookingApproved  NotificationKind = "booking_approved"  // пассажиру
+	NotificationBookingRejected  NotificationKind = "booking_rejected"  // пассажиру
+	NotificationBookingCancelled NotificationKind = "booking_cancelled" // водителю: пассажир отменил заявку
+	NotificationTripMatched      NotificationKind = "trip_matched"      // подписчику: новая поездка по сохранённому поиску
+	NotificationTripCancelled    NotificationKind = "trip_cancelled"    // пассажиру: поездка отменена вместе с заявкой
+)
+
+// NotificationChannel — способ доставки уведомления
+type NotificationChannel string
+
+const (
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelEmail NotificationChannel = "email"
+	ChannelPush  NotificationChannel = "push"
+)
+
+// NotificationStatus — состояние записи в outbox уведомлений
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending" // ждёт отправки или повторной попытки
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed" // попытки исчерпаны
 )
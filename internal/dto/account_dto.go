@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+// PersonalData — всё, что сервис хранит о пользователе; уходит в архив выгрузки
+type PersonalData struct {
+	ExportedAt time.Time   `json:"exported_at"`
+	User       models.User `json:"user"`
+
+	Cars      []models.Car      `json:"cars"`
+	CarPhotos []models.CarPhoto `json:"car_photos"`
+
+	TripsAsDriver []models.Trip    `json:"trips_as_driver"`
+	Bookings      []models.Booking `json:"bookings"`
+
+	ReviewsWritten  []models.Review            `json:"reviews_written"`
+	ReviewsReceived []models.Review            `json:"reviews_received"`
+	ReviewReplies   []models.ReviewReply       `json:"review_replies"`
+	ReviewReports   []models.ReviewReport      `json:"review_reports"`
+	HelpfulVotes    []models.ReviewHelpfulVote `json:"helpful_votes"`
+
+	SavedSearches []models.SavedSearch `json:"saved_searches"`
+	SearchAlerts  []models.SearchAlert `json:"search_alerts"`
+	Blocks        []models.UserBlock   `json:"blocks"`
+
+	VerificationDocuments []models.VerificationDocument `json:"verification_documents"`
+	Sessions              []models.Session              `json:"sessions"`
+	Notifications         []models.Notification         `json:"notifications"`
+}
+
+// DataExportResponse — задание на выгрузку; ссылка на архив есть, только пока он готов
+type DataExportResponse struct {
+	models.DataExport
+
+	DownloadURL string `json:"download_url,omitempty"`
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+type LoginRequest struct {
+	Phone      string `json:"phone" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name" binding:"max=100"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ClientInfo — откуда пришёл запрос; сохраняется в сессии устройства
+type ClientInfo struct {
+	DeviceName string
+	IP         string
+	UserAgent  string
+}
+
+type TokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	SessionID        uint      `json:"session_id"`
+}
+
+// SessionResponse — устройство в списке активных сессий
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	DeviceName string    `json:"device_name"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=128"`
+}
+
+// PasswordResetRequest — администратор задаёт пароль пользователю
+type PasswordResetRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=128"`
+}
+
+type RoleUpdateRequest struct {
+	Role constants.Role `json:"role" binding:"required"`
+}
+
+// AuditFilter — фильтр журнала действий сотрудников
+type AuditFilter struct {
+	ActorID    *uint
+	TargetType string
+	TargetID   *uint
+
+	Page     int
+	PageSize int
+	After    *models.Cursor
+}
@@ -0,0 +1,8 @@
+package dto
+
+// BlockResponse — результат блокировки; CancelledBookings — сколько будущих заявок между парой отменено
+type BlockResponse struct {
+	BlockerID         uint `json:"blocker_id"`
+	BlockedID         uint `json:"blocked_id"`
+	CancelledBookings int  `json:"cancelled_bookings"`
+}
@@ -1,12 +1,102 @@
 package dto
 
-import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
 
 type BookingCreateRequest struct {
 	TripID      uint `json:"trip_id" binding:"required"`
 	PassengerID uint `json:"passenger_id" binding:"required"`
+	Seats       int  `json:"seats" binding:"omitempty,min=1,max=8"`
 }
 
 type BookingUpdateRequest struct {
 	BookingStatus *constants.BookingStatus `json:"booking_status" binding:"required"`
 }
+
+// BookingDecisionRequest — решение водителя по заявке
+type BookingDecisionRequest struct {
+	Status constants.BookingStatus `json:"booking_status" binding:"required,oneof=approved rejected"`
+}
+
+type BookingCancelRequest struct {
+	PassengerID uint `json:"passenger_id" binding:"required"`
+}
+
+const (
+	BookingWhenUpcoming = "upcoming"
+	BookingWhenPast     = "past"
+)
+
+// PassengerBookingFilter — фильтр "моих поездок" пассажира
+type PassengerBookingFilter struct {
+	PassengerID uint
+	Status      *constants.BookingStatus
+	When        string // upcoming | past | "" (все)
+
+	Page     int
+	PageSize int
+	After    *models.Cursor
+}
+
+type BookingTripInfo struct {
+	ID          uint           `json:"id"`
+	FromCity    string         `json:"from_city"`
+	ToCity      string         `json:"to_city"`
+	StartTime   time.Time      `json:"start_time"`
+	DurationMin int            `json:"duration_min"`
+	Price       int            `json:"price"`
+	TripStatus  string         `json:"trip_status"`
+	DriverID    uint           `json:"driver_id"`
+	DriverName  string         `json:"driver_name"`
+	Car         BookingCarInfo `json:"car"`
+}
+
+type BookingCarInfo struct {
+	Brand        string `json:"brand"`
+	CarModel     string `json:"car_model"`
+	Color        string `json:"color"`
+	LicensePlate string `json:"license_plate"`
+}
+
+type PassengerBooking struct {
+	models.Booking
+
+	Trip      BookingTripInfo `json:"trip"`
+	CanCancel bool            `json:"can_cancel"`
+	CanReview bool            `json:"can_review"`
+
+	// для активной заявки — сколько вернётся при отмене сейчас, для закрытой — итог.
+	// Значение справочное: оплата в сервисе не проводится, баланс не меняется
+	RefundPercent int `json:"refund_percent"`
+}
+
+type BookingCancelResponse struct {
+	BookingID uint                    `json:"booking_id"`
+	Status    constants.BookingStatus `json:"status"`
+
+	// справочно, как и в PassengerBooking: на баланс не зачисляется
+	RefundPercent int  `json:"refund_percent"`
+	Informational bool `json:"refund_informational"`
+}
+
+// BookingDriverActionRequest — действие водителя над заявкой (посадка, неявка)
+type BookingDriverActionRequest struct {
+	DriverID uint `json:"driver_id" binding:"required"`
+}
+
+type PassengerRatingInfo struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+}
+
+// PendingBooking — заявка во входящих водителя вместе с рейтингом пассажира
+type PendingBooking struct {
+	models.Booking
+
+	PassengerName   string              `json:"passenger_name"`
+	PassengerRating PassengerRatingInfo `json:"passenger_rating"`
+}
@@ -1,14 +1,36 @@
 package dto
 
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
 type CarCreateRequest struct {
 	OwnerID  uint   `json:"owner_id"`
 	Brand    string `json:"brand"`
 	CarModel string `json:"car_model"`
 	Seats    int    `json:"seats"`
+
+	LicensePlate string                `json:"license_plate"`
+	Color        string                `json:"color"`
+	Year         int                   `json:"year"`
+	BodyType     constants.CarBodyType `json:"body_type"`
+
+	AirConditioning  bool `json:"air_conditioning"`
+	ChildSeat        bool `json:"child_seat"`
+	TrunkCapacityL   int  `json:"trunk_capacity_l"`
+	WheelchairAccess bool `json:"wheelchair_access"`
 }
 
 type CarUpdateRequest struct {
 	Brand    *string `json:"brand"`
 	CarModel *string `json:"car_model"`
 	Seats    *int    `json:"seats"`
+
+	LicensePlate *string                `json:"license_plate"`
+	Color        *string                `json:"color"`
+	Year         *int                   `json:"year"`
+	BodyType     *constants.CarBodyType `json:"body_type"`
+
+	AirConditioning  *bool `json:"air_conditioning"`
+	ChildSeat        *bool `json:"child_seat"`
+	TrunkCapacityL   *int  `json:"trunk_capacity_l"`
+	WheelchairAccess *bool `json:"wheelchair_access"`
 }
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+// DriverTripSummary — поездка в кабинете водителя вместе со сводкой по заявкам
+type DriverTripSummary struct {
+	models.Trip
+
+	PendingBookings  int64 `json:"pending_bookings"`
+	ApprovedBookings int64 `json:"approved_bookings"`
+	ApprovedSeats    int64 `json:"approved_seats"`
+	SeatsLeft        int   `json:"seats_left"`
+}
+
+type DriverDashboard struct {
+	Upcoming   []DriverTripSummary `json:"upcoming"`
+	InProgress []DriverTripSummary `json:"in_progress"`
+	Past       []DriverTripSummary `json:"past"`
+}
+
+type ManifestPassenger struct {
+	BookingID   uint   `json:"booking_id"`
+	PassengerID uint   `json:"passenger_id"`
+	Name        string `json:"name"`
+	Phone       string `json:"phone"`
+	PhoneMasked bool   `json:"phone_masked"`
+	Seats       int    `json:"seats"`
+}
+
+type TripManifest struct {
+	TripID        uint                `json:"trip_id"`
+	FromCity      string              `json:"from_city"`
+	ToCity        string              `json:"to_city"`
+	StartTime     time.Time           `json:"start_time"`
+	TotalSeats    int                 `json:"total_seats"`
+	BookedSeats   int                 `json:"booked_seats"`
+	PhonesVisible time.Time           `json:"phones_visible_from"`
+	Passengers    []ManifestPassenger `json:"passengers"`
+}
+
+// DriverRatingSummary — агрегированный рейтинг водителя
+type DriverRatingSummary struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+
+	// Distribution — число отзывов на каждую оценку, ключи от 1 до 5
+	Distribution map[int]int `json:"distribution"`
+
+	// Categories — средние по категориям (пунктуальность, безопасность, комфорт, общение)
+	Categories map[constants.ReviewCategory]models.CategoryRating `json:"categories"`
+}
+
+type DriverProfileReview struct {
+	ID         uint      `json:"id"`
+	TripID     uint      `json:"trip_id"`
+	AuthorName string    `json:"author_name"`
+	Rating     int       `json:"rating"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Reply *DriverProfileReply `json:"reply,omitempty"`
+}
+
+// DriverProfileReply — ответ водителя на отзыв
+type DriverProfileReply struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DriverProfile — публичная карточка водителя; телефон и баланс сюда не попадают
+type DriverProfile struct {
+	PublicProfile
+
+	CompletedTrips int64 `json:"completed_trips"`
+
+	Rating        DriverRatingSummary   `json:"rating"`
+	RecentReviews []DriverProfileReview `json:"recent_reviews"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// ListResponse — общий конверт для всех списков
+type ListResponse[T any] struct {
+	Items    []T   `json:"items"`
+	Page     int   `json:"page,omitempty"`
+	PageSize int   `json:"page_size"`
+	Total    int64 `json:"total"`
+	HasNext  bool  `json:"has_next"`
+
+	// NextCursor — курсор следующей страницы для ?cursor=
+	NextCursor string `json:"next_cursor,omitempty"`
+}
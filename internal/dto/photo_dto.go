@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type PhotoResponse struct {
+	ID        uint      `json:"id,omitempty"`
+	URL       string    `json:"url"`
+	ThumbURL  string    `json:"thumb_url"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
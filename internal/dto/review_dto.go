@@ -1,10 +1,164 @@
 package dto
 
+import (
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
 type ReviewCreateRequest struct {
-	Text   string `json:"text" binding:"required,min=3"`
-	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Text string `json:"text" binding:"required,min=3"`
+
+	// Rating можно не указывать, если заполнены оценки по категориям — тогда он выводится из них
+	Rating     int                `json:"rating" binding:"omitempty,min=1,max=5"`
+	SubRatings *SubRatingsRequest `json:"sub_ratings"`
+
+	// TargetID — оцениваемый пассажир; обязателен, если отзыв пишет водитель
+	TargetID *uint `json:"target_id"`
 }
 type ReviewUpdateRequest struct {
-	Text   *string `json:"text"`
-	Rating *int    `json:"rating"`
+	Text       *string            `json:"text"`
+	Rating     *int               `json:"rating" binding:"omitempty,min=1,max=5"`
+	SubRatings *SubRatingsRequest `json:"sub_ratings"`
+}
+
+// SubRatingsRequest — оценки по категориям: nil означает "не указано"/"не менять",
+// 0 при правке убирает оценку категории
+type SubRatingsRequest struct {
+	Punctuality   *int `json:"punctuality" binding:"omitempty,min=0,max=5"`
+	DrivingSafety *int `json:"driving_safety" binding:"omitempty,min=0,max=5"`
+	CarComfort    *int `json:"car_comfort" binding:"omitempty,min=0,max=5"`
+	Communication *int `json:"communication" binding:"omitempty,min=0,max=5"`
+}
+
+func (r *SubRatingsRequest) Apply(base models.SubRatings) models.SubRatings {
+	if r == nil {
+		return base
+	}
+
+	for _, f := range []struct {
+		req *int
+		dst **int
+	}{
+		{r.Punctuality, &base.Punctuality},
+		{r.DrivingSafety, &base.DrivingSafety},
+		{r.CarComfort, &base.CarComfort},
+		{r.Communication, &base.Communication},
+	} {
+		switch {
+		case f.req == nil:
+		case *f.req == 0:
+			*f.dst = nil
+		default:
+			*f.dst = f.req
+		}
+	}
+	return base
+}
+
+// ReviewFilter — выборка отзывов для страниц поездки, водителя и автора
+type ReviewFilter struct {
+	TripID   *uint
+	DriverID *uint // отзывы пассажиров о водителе
+	AuthorID *uint
+
+	MinRating *int
+	MaxRating *int
+
+	// SortBy — ReviewSortNewest (по умолчанию), ReviewSortHighest, ReviewSortLowest или ReviewSortHelpful
+	SortBy string
+
+	Page     int
+	PageSize int
+	After    *models.Cursor
+}
+
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortHighest = "highest"
+	ReviewSortLowest  = "lowest"
+	ReviewSortHelpful = "most_helpful"
+)
+
+// ReviewSummary — сводка по выборке отзывов без учёта фильтра по оценке
+type ReviewSummary struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+
+	// Distribution — число отзывов на каждую оценку, ключи от 1 до 5
+	Distribution map[int]int `json:"distribution"`
+}
+
+// ReviewListResponse — страница отзывов со сводкой
+type ReviewListResponse struct {
+	ListResponse[models.Review]
+
+	Summary ReviewSummary `json:"summary"`
+}
+
+type ReviewHelpfulResponse struct {
+	ReviewID     uint `json:"review_id"`
+	HelpfulCount int  `json:"helpful_count"`
+}
+
+type ReviewReportRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
+
+// ReviewModerationRequest — решение модератора; причина обязательна при скрытии
+type ReviewModerationRequest struct {
+	ModeratorID uint   `json:"moderator_id" binding:"required"`
+	Reason      string `json:"reason" binding:"max=500"`
+}
+
+// ModeratedReview — отзыв вместе с полями модерации, только для админки
+type ModeratedReview struct {
+	models.Review
+
+	Flags            []string   `json:"moderation_flags"`
+	ModerationReason string     `json:"moderation_reason,omitempty"`
+	ModeratedBy      *uint      `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+}
+
+func NewModeratedReview(review models.Review) ModeratedReview {
+	return ModeratedReview{
+		Review:           review,
+		Flags:            splitFlags(review.ModerationFlags),
+		ModerationReason: review.ModerationReason,
+		ModeratedBy:      review.ModeratedBy,
+		ModeratedAt:      review.ModeratedAt,
+	}
+}
+
+type ReviewReplyRequest struct {
+	Text string `json:"text" binding:"required,min=2,max=1000"`
+}
+
+// ModeratedReply — ответ на отзыв с полями модерации, только для админки
+type ModeratedReply struct {
+	models.ReviewReply
+
+	Flags            []string   `json:"moderation_flags"`
+	ModerationReason string     `json:"moderation_reason,omitempty"`
+	ModeratedBy      *uint      `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+}
+
+func NewModeratedReply(reply models.ReviewReply) ModeratedReply {
+	return ModeratedReply{
+		ReviewReply:      reply,
+		Flags:            splitFlags(reply.ModerationFlags),
+		ModerationReason: reply.ModerationReason,
+		ModeratedBy:      reply.ModeratedBy,
+		ModeratedAt:      reply.ModeratedAt,
+	}
+}
+
+func splitFlags(flags string) []string {
+	if flags == "" {
+		return []string{}
+	}
+	return strings.Split(flags, ",")
 }
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type SavedSearchCreateRequest struct {
+	FromCity string     `json:"from_city" binding:"required"`
+	ToCity   string     `json:"to_city" binding:"required"`
+	DateFrom *time.Time `json:"date_from"`
+	DateTo   *time.Time `json:"date_to"`
+	Seats    int        `json:"seats" binding:"omitempty,min=1,max=8"`
+	MaxPrice *int       `json:"max_price" binding:"omitempty,min=0"`
+}
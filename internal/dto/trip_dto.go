@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
 )
 
 type TripCreateRequest struct {
@@ -14,6 +15,40 @@ type TripCreateRequest struct {
 	AvailableSeats int                  `json:"available_seats"`
 	Price          int                  `json:"price"`
 	TripStatus     constants.TripStatus `json:"trip_status"`
+
+	// Если не передано, берутся настройки водителя по умолчанию
+	Preferences *TripPreferencesRequest `json:"preferences"`
+}
+
+// TripPreferencesRequest — частичное изменение условий поездки: nil означает "не менять"
+type TripPreferencesRequest struct {
+	SmokingAllowed *bool `json:"smoking_allowed"`
+	PetsAllowed    *bool `json:"pets_allowed"`
+	MusicAllowed   *bool `json:"music_allowed"`
+	BigLuggage     *bool `json:"big_luggage"`
+	WomenOnly      *bool `json:"women_only"`
+}
+
+func (r *TripPreferencesRequest) Apply(base models.TripPreferences) models.TripPreferences {
+	if r == nil {
+		return base
+	}
+	if r.SmokingAllowed != nil {
+		base.SmokingAllowed = *r.SmokingAllowed
+	}
+	if r.PetsAllowed != nil {
+		base.PetsAllowed = *r.PetsAllowed
+	}
+	if r.MusicAllowed != nil {
+		base.MusicAllowed = *r.MusicAllowed
+	}
+	if r.BigLuggage != nil {
+		base.BigLuggage = *r.BigLuggage
+	}
+	if r.WomenOnly != nil {
+		base.WomenOnly = *r.WomenOnly
+	}
+	return base
 }
 
 type TripFilter struct {
@@ -23,10 +58,40 @@ type TripFilter struct {
 	AvailableSeats *int
 	TripStatus     *constants.TripStatus
 
+	// Фильтры по автомобилю поездки
+	CarColor         *string
+	CarBodyType      *constants.CarBodyType
+	MinCarYear       *int
+	AirConditioning  *bool
+	ChildSeat        *bool
+	WheelchairAccess *bool
+	MinTrunkCapacity *int
+
+	// Фильтры по условиям поездки
+	SmokingAllowed *bool
+	PetsAllowed    *bool
+	MusicAllowed   *bool
+	BigLuggage     *bool
+	WomenOnly      *bool
+
+	MinDriverRating *float64
+
+	// ViewerID — кто ищет: поездки водителей, с которыми он в блокировке, не показываются
+	ViewerID *uint
+
+	// SortBy — TripSortStartTime (по умолчанию) или TripSortDriverRating
+	SortBy string
+
 	Page     int
 	PageSize int
+	After    *models.Cursor
 }
 
+const (
+	TripSortStartTime    = "start_time"
+	TripSortDriverRating = "driver_rating"
+)
+
 type TripUpdateRequest struct {
 	FromCity       *string               `json:"from_city"`
 	ToCity         *string               `json:"to_city"`
@@ -35,4 +100,11 @@ type TripUpdateRequest struct {
 	AvailableSeats *int                  `json:"available_seats"`
 	Price          *int                  `json:"price"`
 	TripStatus     *constants.TripStatus `json:"trip_status"`
+
+	Preferences *TripPreferencesRequest `json:"preferences"`
+}
+
+// TripDriverActionRequest — запуск и завершение поездки водителем
+type TripDriverActionRequest struct {
+	DriverID uint `json:"driver_id" binding:"required"`
 }
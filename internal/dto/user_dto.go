@@ -1,12 +1,100 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
 type UserCreateRequest struct {
-	Name    string `json:"name"`
-	Phone   string `json:"phone"`
-	Balance int    `json:"balance"`
+	Name     string           `json:"name"`
+	Phone    string           `json:"phone"`
+	Password string           `json:"password" binding:"required,min=8,max=128"`
+	Balance  int              `json:"balance"`
+	Gender   constants.Gender `json:"gender"`
+
+	Profile *UserProfileRequest `json:"profile"`
+
+	DefaultPreferences *TripPreferencesRequest `json:"default_preferences"`
 }
 
 type UserUpdateRequest struct {
-	Name  *string `json:"name"`
-	Phone *string `json:"phone"`
+	Name   *string           `json:"name"`
+	Phone  *string           `json:"phone"`
+	Gender *constants.Gender `json:"gender"`
+
+	Profile *UserProfileRequest `json:"profile"`
+
+	DefaultPreferences *TripPreferencesRequest `json:"default_preferences"`
+}
+
+// UserProfileRequest — частичное изменение профиля: nil означает "не менять",
+// пустая строка или пустой список — очистить поле
+type UserProfileRequest struct {
+	Bio              *string                  `json:"bio"`
+	BirthYear        *int                     `json:"birth_year"`
+	Languages        *[]string                `json:"languages"`
+	PreferredContact *constants.ContactMethod `json:"preferred_contact"`
+}
+
+func (r *UserProfileRequest) Apply(base models.UserProfile) models.UserProfile {
+	if r == nil {
+		return base
+	}
+	if r.Bio != nil {
+		base.Bio = *r.Bio
+	}
+	if r.BirthYear != nil {
+		base.BirthYear = r.BirthYear
+		// 0 — убрать год рождения из профиля
+		if *r.BirthYear == 0 {
+			base.BirthYear = nil
+		}
+	}
+	if r.Languages != nil {
+		base.Languages = models.Languages(*r.Languages)
+	}
+	if r.PreferredContact != nil {
+		base.PreferredContact = *r.PreferredContact
+	}
+	return base
+}
+
+// PublicProfile — то, что видят другие пользователи: без телефона, баланса и точной даты рождения
+type PublicProfile struct {
+	ID               uint                    `json:"id"`
+	Name             string                  `json:"name"`
+	Gender           constants.Gender        `json:"gender,omitempty"`
+	Age              *int                    `json:"age,omitempty"`
+	Bio              string                  `json:"bio"`
+	Languages        []string                `json:"languages"`
+	PreferredContact constants.ContactMethod `json:"preferred_contact,omitempty"`
+	MemberSince      time.Time               `json:"member_since"`
+	DriverVerified   bool                    `json:"driver_verified"`
+}
+
+func NewPublicProfile(user *models.User, now time.Time) PublicProfile {
+	profile := PublicProfile{
+		ID:               user.ID,
+		Name:             user.Name,
+		Gender:           user.Gender,
+		Bio:              user.Bio,
+		Languages:        []string(user.Languages),
+		PreferredContact: user.PreferredContact,
+		MemberSince:      user.CreatedAt,
+		DriverVerified:   user.DriverVerified,
+	}
+
+	if profile.Languages == nil {
+		profile.Languages = []string{}
+	}
+
+	// возраст с точностью до года: день рождения не храним
+	if user.BirthYear != nil {
+		age := now.Year() - *user.BirthYear
+		profile.Age = &age
+	}
+
+	return profile
 }
@@ -0,0 +1,7 @@
+package dto
+
+// VerificationDecisionRequest — решение администратора по документу; причина обязательна при отказе
+type VerificationDecisionRequest struct {
+	ReviewerID uint   `json:"reviewer_id" binding:"required"`
+	Reason     string `json:"reason" binding:"max=500"`
+}
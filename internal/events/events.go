@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+type Type string
+
+const (
+	TripStarted   Type = "trip.started"
+	TripCompleted Type = "trip.completed"
+)
+
+// Event — доменное событие. Поля кроме Type и OccurredAt заполняются в зависимости от типа.
+type Event struct {
+	Type       Type
+	TripID     uint
+	OccurredAt time.Time
+}
+
+// Handler обрабатывает событие; ошибка логируется шиной и не мешает остальным подписчикам
+type Handler func(ctx context.Context, e Event) error
+
+type Bus interface {
+	Publish(ctx context.Context, e Event)
+
+	// Subscribe регистрирует обработчик; name используется в логах
+	Subscribe(t Type, name string, h Handler)
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// memoryBus доставляет события синхронно в процессе приложения.
+// Подписчики вызываются по порядку регистрации; паника одного не роняет остальных.
+type memoryBus struct {
+	mu     sync.RWMutex
+	subs   map[Type][]subscriber
+	logger *slog.Logger
+}
+
+func NewMemoryBus(logger *slog.Logger) Bus {
+	return &memoryBus{
+		subs:   make(map[Type][]subscriber),
+		logger: logger,
+	}
+}
+
+func (b *memoryBus) Subscribe(t Type, name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs[t] = append(b.subs[t], subscriber{name: name, handler: h})
+}
+
+func (b *memoryBus) Publish(ctx context.Context, e Event) {
+	b.mu.RLock()
+	subs := append([]subscriber(nil), b.subs[e.Type]...)
+	b.mu.RUnlock()
+
+	b.logger.Debug("event published",
+		slog.String("type", string(e.Type)),
+		slog.Uint64("trip_id", uint64(e.TripID)),
+		slog.Int("subscribers", len(subs)),
+	)
+
+	for _, sub := range subs {
+		if err := b.dispatch(ctx, sub, e); err != nil {
+			b.logger.Error("event handler failed",
+				slog.String("type", string(e.Type)),
+				slog.String("subscriber", sub.name),
+				slog.Uint64("trip_id", uint64(e.TripID)),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func (b *memoryBus) dispatch(ctx context.Context, sub subscriber, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return sub.handler(ctx, e)
+}
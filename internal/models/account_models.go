@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// DataExport — задание на выгрузку всех данных пользователя в ZIP-архив
+type DataExport struct {
+	Base
+
+	UserID uint                   `json:"user_id" gorm:"not null;index"`
+	Status constants.ExportStatus `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	Key    string                 `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	Size   int64                  `json:"size" gorm:"not null;default:0"`
+	Error  string                 `json:"error,omitempty" gorm:"type:text;not null;default:''"`
+
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AccountErasure — запись об удалении аккаунта; персональных данных в ней нет
+type AccountErasure struct {
+	Base
+
+	UserID      uint `json:"user_id" gorm:"not null;uniqueIndex"`
+	RequestedBy uint `json:"requested_by" gorm:"not null"`
+
+	// ForfeitedBalance — остаток на балансе, от которого пользователь отказался
+	ForfeitedBalance  int `json:"forfeited_balance" gorm:"not null;default:0"`
+	CancelledTrips    int `json:"cancelled_trips" gorm:"not null;default:0"`
+	CancelledBookings int `json:"cancelled_bookings" gorm:"not null;default:0"`
+}
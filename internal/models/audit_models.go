@@ -0,0 +1,19 @@
+package models
+
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+// AuditEntry — запись журнала действий сотрудников в админских ручках
+type AuditEntry struct {
+	Base
+
+	ActorID   uint           `json:"actor_id" gorm:"not null;index"`
+	ActorRole constants.Role `json:"actor_role" gorm:"type:varchar(20);not null"`
+
+	// Action — метод и шаблон пути, например "POST /admin/reviews/:id/hide"
+	Action     string `json:"action" gorm:"type:varchar(255);not null;index"`
+	TargetType string `json:"target_type" gorm:"type:varchar(50);not null;index:idx_audit_target"`
+	TargetID   *uint  `json:"target_id,omitempty" gorm:"index:idx_audit_target"`
+
+	Status int    `json:"status" gorm:"not null"`
+	IP     string `json:"ip" gorm:"type:varchar(64);not null;default:''"`
+}
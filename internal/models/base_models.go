@@ -12,3 +12,8 @@ type Base struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
+
+// CreatedCursor — позиция записи в списках, отсортированных по created_at
+func (b Base) CreatedCursor() Cursor {
+	return Cursor{Key: b.CreatedAt, ID: b.ID}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserBlock — пользователь BlockerID заблокировал BlockedID. Блокировка действует
+// в обе стороны: пара не видит поездок друг друга и не может бронировать их
+type UserBlock struct {
+	BlockerID uint      `json:"blocker_id" gorm:"primaryKey;autoIncrement:false"`
+	BlockedID uint      `json:"blocked_id" gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
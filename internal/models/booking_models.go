@@ -1,11 +1,17 @@
 package models
 
-import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
 
 type Booking struct {
 	Base
 
-	TripID        uint   `json:"trip_id" gorm:"not null;index"`
-	PassengerID   uint   `json:"passenger_id" gorm:"not null;index"`
+	TripID        uint                    `json:"trip_id" gorm:"not null;index"`
+	PassengerID   uint                    `json:"passenger_id" gorm:"not null;index"`
+	Seats         int                     `json:"seats" gorm:"not null;default:1;check:seats > 0"`
 	BookingStatus constants.BookingStatus `json:"booking_status" gorm:"type:varchar(50);not null;index"`
+	CheckedInAt   *time.Time              `json:"checked_in_at"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// PassengerBookingRow — заявка пассажира вместе с данными поездки, водителя и автомобиля
+type PassengerBookingRow struct {
+	Booking
+
+	FromCity    string
+	ToCity      string
+	StartTime   time.Time
+	DurationMin int
+	Price       int
+	TripStatus  string
+	DriverID    uint
+	DriverName  string
+	FinishedAt  *time.Time
+
+	CarBrand        string
+	CarModel        string
+	CarColor        string
+	CarLicensePlate string
+
+	HasReview       bool
+	TripHasCheckIns bool
+}
+
+// PendingBookingRow — заявка во входящих водителя вместе с именем и рейтингом пассажира
+type PendingBookingRow struct {
+	Booking
+
+	PassengerName    string
+	PassengerRating  float64
+	PassengerReviews int
+}
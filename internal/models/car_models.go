@@ -1,5 +1,11 @@
 package models
 
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
 type Car struct {
 	Base
 
@@ -7,4 +13,19 @@ type Car struct {
 	Brand    string `json:"brand" gorm:"type:varchar(255);not null"`
 	CarModel string `json:"car_model" gorm:"type:varchar(255);not null"`
 	Seats    int    `json:"seats" gorm:"not null;check:seats > 0"`
+
+	// Номер хранится в нормализованном виде и уникален среди неудалённых автомобилей
+	LicensePlate string                `json:"license_plate" gorm:"type:varchar(20);not null;default:'';index:idx_cars_license_plate_active,unique,where:deleted_at IS NULL AND license_plate <> ''"`
+	Color        string                `json:"color" gorm:"type:varchar(50);not null;default:''"`
+	Year         int                   `json:"year" gorm:"not null;default:0"`
+	BodyType     constants.CarBodyType `json:"body_type" gorm:"type:varchar(50);not null;default:'';index"`
+
+	AirConditioning  bool `json:"air_conditioning" gorm:"not null;default:false"`
+	ChildSeat        bool `json:"child_seat" gorm:"not null;default:false"`
+	TrunkCapacityL   int  `json:"trunk_capacity_l" gorm:"not null;default:0;check:trunk_capacity_l >= 0"`
+	WheelchairAccess bool `json:"wheelchair_access" gorm:"not null;default:false"`
+
+	// Бейдж проверенного автомобиля: одобрено СТС; сбрасывается при смене номера
+	Verified   bool       `json:"verified" gorm:"not null;default:false"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
 }
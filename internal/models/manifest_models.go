@@ -0,0 +1,18 @@
+package models
+
+// BookingCounts — агрегат по заявкам одной поездки
+type BookingCounts struct {
+	TripID        uint
+	Pending       int64
+	Approved      int64
+	ApprovedSeats int64
+}
+
+// ManifestRow — одобренная заявка вместе с данными пассажира
+type ManifestRow struct {
+	BookingID   uint
+	PassengerID uint
+	Name        string
+	Phone       string
+	Seats       int
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// Notification — запись outbox: пишется в той же транзакции, что и событие,
+// и отправляется воркером. Текст рендерится сразу, на языке получателя
+type Notification struct {
+	Base
+
+	UserID  uint                          `json:"user_id" gorm:"not null;index"`
+	Kind    constants.NotificationKind    `json:"kind" gorm:"type:varchar(50);not null"`
+	Channel constants.NotificationChannel `json:"channel" gorm:"type:varchar(10);not null"`
+	Locale  string                        `json:"locale" gorm:"type:varchar(5);not null"`
+
+	// Recipient — адрес в канале: телефон для SMS, почта для email, ID пользователя для push
+	Recipient string `json:"-" gorm:"type:varchar(255);not null"`
+	Subject   string `json:"subject" gorm:"type:varchar(255);not null;default:''"`
+	Body      string `json:"body" gorm:"type:text;not null"`
+
+	Status        constants.NotificationStatus `json:"status" gorm:"type:varchar(20);not null;default:pending;index:idx_notifications_due,priority:1"`
+	Attempts      int                          `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time                    `json:"next_attempt_at" gorm:"not null;index:idx_notifications_due,priority:2"`
+	LastError     string                       `json:"last_error,omitempty" gorm:"type:text;not null;default:''"`
+	SentAt        *time.Time                   `json:"sent_at,omitempty"`
+}
@@ -1,6 +1,38 @@
 package models
 
+import "time"
+
 type Page struct {
 	Page     int
 	PageSize int
+
+	// After — позиция keyset-пагинации; если задана, Page игнорируется
+	After *Cursor `form:"-"`
+}
+
+// Cursor — позиция в отсортированном списке: значение ключа сортировки и ID как тай-брейк.
+// Score используется, только если список дополнительно отсортирован по числовому показателю.
+// AsOf — момент, на который посчитан Score, если показатель меняется со временем.
+type Cursor struct {
+	Score float64
+	Key   time.Time
+	ID    uint
+	AsOf  time.Time
+}
+
+// PageInfo — сведения о странице, которые репозиторий возвращает вместе с данными
+type PageInfo struct {
+	// Page — номер страницы; 0, если список читается по курсору
+	Page     int
+	PageSize int
+
+	// Total — число записей, подходящих под фильтр, без учёта пагинации
+	Total int64
+
+	// Next — курсор следующей страницы; nil, если данных больше нет
+	Next *Cursor
+}
+
+func (p PageInfo) HasNext() bool {
+	return p.Next != nil
 }
@@ -0,0 +1,12 @@
+package models
+
+type CarPhoto struct {
+	Base
+
+	CarID       uint   `json:"car_id" gorm:"not null;index"`
+	Key         string `json:"-" gorm:"type:varchar(255);not null"`
+	ThumbKey    string `json:"-" gorm:"type:varchar(255);not null"`
+	ContentType string `json:"content_type" gorm:"type:varchar(50);not null"`
+	Width       int    `json:"width" gorm:"not null"`
+	Height      int    `json:"height" gorm:"not null"`
+}
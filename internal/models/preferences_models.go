@@ -0,0 +1,22 @@
+package models
+
+// TripPreferences — правила поездки, которые пассажиры обычно уточняют заранее.
+// Встраивается в Trip (условия конкретной поездки) и в User (значения по умолчанию водителя).
+type TripPreferences struct {
+	SmokingAllowed bool `json:"smoking_allowed" gorm:"not null;default:false"`
+	PetsAllowed    bool `json:"pets_allowed" gorm:"not null;default:false"`
+	MusicAllowed   bool `json:"music_allowed" gorm:"not null;default:false"`
+	BigLuggage     bool `json:"big_luggage" gorm:"not null;default:false"`
+	WomenOnly      bool `json:"women_only" gorm:"not null;default:false"`
+}
+
+// Columns возвращает значения в виде колонок с префиксом, чтобы обновлять и false-значения
+func (p TripPreferences) Columns(prefix string) map[string]any {
+	return map[string]any{
+		prefix + "smoking_allowed": p.SmokingAllowed,
+		prefix + "pets_allowed":    p.PetsAllowed,
+		prefix + "music_allowed":   p.MusicAllowed,
+		prefix + "big_luggage":     p.BigLuggage,
+		prefix + "women_only":      p.WomenOnly,
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// UserProfile — необязательные сведения о пользователе для карточки в поиске и заявках.
+// Встраивается в User без префикса.
+type UserProfile struct {
+	Bio              string                  `json:"bio" gorm:"type:text;not null;default:''"`
+	BirthYear        *int                    `json:"birth_year"`
+	Languages        Languages               `json:"languages" gorm:"type:varchar(100);not null;default:''"`
+	PreferredContact constants.ContactMethod `json:"preferred_contact" gorm:"type:varchar(20);not null;default:''"`
+}
+
+// Columns возвращает поля профиля колонками, чтобы обновлять и пустые значения
+func (p UserProfile) Columns() map[string]any {
+	return map[string]any{
+		"bio":               p.Bio,
+		"birth_year":        p.BirthYear,
+		"languages":         p.Languages,
+		"preferred_contact": p.PreferredContact,
+	}
+}
+
+// Languages — коды языков ISO 639-1; в базе хранятся строкой через запятую
+type Languages []string
+
+func (l Languages) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+func (l *Languages) Scan(src any) error {
+	var raw string
+
+	switch v := src.(type) {
+	case nil:
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("languages: unsupported type %T", src)
+	}
+
+	*l = Languages{}
+	if raw != "" {
+		*l = strings.Split(raw, ",")
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// DriverRating — агрегат отзывов о водителе по всем его поездкам.
+// Обновляется инкрементально в той же транзакции, что и сам отзыв.
+type DriverRating struct {
+	DriverID uint `json:"driver_id" gorm:"primaryKey;autoIncrement:false"`
+
+	ReviewsCount int     `json:"reviews_count" gorm:"not null;default:0"`
+	RatingSum    int     `json:"-" gorm:"not null;default:0"`
+	AvgRating    float64 `json:"avg_rating" gorm:"not null;default:0;index"`
+
+	// Распределение оценок по звёздам
+	Stars1 int `json:"-" gorm:"column:stars_1;not null;default:0"`
+	Stars2 int `json:"-" gorm:"column:stars_2;not null;default:0"`
+	Stars3 int `json:"-" gorm:"column:stars_3;not null;default:0"`
+	Stars4 int `json:"-" gorm:"column:stars_4;not null;default:0"`
+	Stars5 int `json:"-" gorm:"column:stars_5;not null;default:0"`
+
+	// Суммы и число оценок по категориям: категории необязательны, поэтому счётчики свои
+	PunctualitySum     int `json:"-" gorm:"not null;default:0"`
+	PunctualityCount   int `json:"-" gorm:"not null;default:0"`
+	DrivingSafetySum   int `json:"-" gorm:"not null;default:0"`
+	DrivingSafetyCount int `json:"-" gorm:"not null;default:0"`
+	CarComfortSum      int `json:"-" gorm:"not null;default:0"`
+	CarComfortCount    int `json:"-" gorm:"not null;default:0"`
+	CommunicationSum   int `json:"-" gorm:"not null;default:0"`
+	CommunicationCount int `json:"-" gorm:"not null;default:0"`
+
+	// Ранжирование в поиске: суммы оценок с экспоненциальным затуханием по давности отзыва,
+	// приведённые к моменту DecayedAt. Байесовская оценка по ним считается в запросе
+	// на нужный момент (см. RatingScoring). Показывается AvgRating.
+	DecayedSum   float64   `json:"-" gorm:"not null;default:0"`
+	DecayedCount float64   `json:"-" gorm:"not null;default:0"`
+	DecayedAt    time.Time `json:"-" gorm:"not null;default:CURRENT_TIMESTAMP"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RatingScoring — параметры ранжирующей оценки водителя:
+// score = (PriorWeight*PriorMean + Σ w·rating) / (PriorWeight + Σ w), w = 0.5^(возраст/HalfLife)
+type RatingScoring struct {
+	// PriorMean — оценка, к которой тянется водитель с малым числом отзывов
+	PriorMean float64
+	// PriorWeight — сколько «виртуальных» отзывов весит PriorMean, должен быть больше нуля
+	PriorWeight float64
+	// HalfLife — за это время вес отзыва падает вдвое
+	HalfLife time.Duration
+}
+
+var DefaultRatingScoring = RatingScoring{
+	PriorMean:   4.0,
+	PriorWeight: 10,
+	HalfLife:    180 * 24 * time.Hour,
+}
+
+// Weight — вес отзыва, оставленного в at, на момент now
+func (s RatingScoring) Weight(at, now time.Time) float64 {
+	age := now.Sub(at)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, age.Seconds()/s.HalfLife.Seconds())
+}
+
+// Distribution возвращает число отзывов на каждую оценку от 1 до 5
+func (r DriverRating) Distribution() map[int]int {
+	return map[int]int{
+		1: r.Stars1,
+		2: r.Stars2,
+		3: r.Stars3,
+		4: r.Stars4,
+		5: r.Stars5,
+	}
+}
+
+// CategoryRating — средняя оценка водителя в одной категории
+type CategoryRating struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+}
+
+// Categories возвращает средние по категориям; у категорий без оценок среднее нулевое
+func (r DriverRating) Categories() map[constants.ReviewCategory]CategoryRating {
+	totals := map[constants.ReviewCategory][2]int{
+		constants.CategoryPunctuality:   {r.PunctualitySum, r.PunctualityCount},
+		constants.CategoryDrivingSafety: {r.DrivingSafetySum, r.DrivingSafetyCount},
+		constants.CategoryCarComfort:    {r.CarComfortSum, r.CarComfortCount},
+		constants.CategoryCommunication: {r.CommunicationSum, r.CommunicationCount},
+	}
+
+	categories := make(map[constants.ReviewCategory]CategoryRating, len(totals))
+	for category, t := range totals {
+		c := CategoryRating{Count: t[1]}
+		if t[1] > 0 {
+			c.Average = float64(t[0]) / float64(t[1])
+		}
+		categories[category] = c
+	}
+	return categories
+}
+
+// DriverReviewRow — отзыв о поездке водителя вместе с именем автора и ответом водителя
+type DriverReviewRow struct {
+	Review
+
+	AuthorName string
+
+	ReplyText      *string
+	ReplyCreatedAt *time.Time
+}
+
+// PassengerRating — агрегат отзывов водителей о пассажире
+type PassengerRating struct {
+	PassengerID uint `json:"passenger_id" gorm:"primaryKey;autoIncrement:false"`
+
+	ReviewsCount int     `json:"reviews_count" gorm:"not null;default:0"`
+	RatingSum    int     `json:"-" gorm:"not null;default:0"`
+	AvgRating    float64 `json:"avg_rating" gorm:"not null;default:0"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
 type Review struct {
 	Base
 
@@ -7,4 +13,67 @@ type Review struct {
 	TripID   uint   `json:"trip_id" gorm:"not null;index"`
 	Text     string `json:"text" gorm:"type:text;not null"`
 	Rating   int    `json:"rating" gorm:"not null;check:rating >= 1 AND rating <= 5"`
+
+	// SubRatings — оценки по категориям; RatingDerived — Rating выведен из них, а не указан автором
+	SubRatings    SubRatings `json:"sub_ratings" gorm:"embedded;embeddedPrefix:sub_"`
+	RatingDerived bool       `json:"rating_derived" gorm:"not null;default:false"`
+
+	// Direction — кто кого оценивает; TargetID — оцениваемый пользователь
+	Direction constants.ReviewDirection `json:"direction" gorm:"type:varchar(30);not null;default:passenger_to_driver;index"`
+	TargetID  uint                      `json:"target_id" gorm:"not null;default:0;index"`
+
+	// Двойное слепое рецензирование: запечатанный отзыв не виден второй стороне и не входит
+	// в рейтинги, пока не оставлен встречный отзыв или не наступил RevealAt
+	Sealed   bool       `json:"sealed" gorm:"not null;default:false;index"`
+	RevealAt *time.Time `json:"reveal_at,omitempty"`
+
+	// Модерация: в рейтинги и публичные списки попадают только опубликованные отзывы
+	Status           constants.ReviewStatus `json:"status" gorm:"type:varchar(30);not null;default:published;index"`
+	ModerationFlags  string                 `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	ModerationReason string                 `json:"-" gorm:"type:text;not null;default:''"`
+	ModeratedBy      *uint                  `json:"-"`
+	ModeratedAt      *time.Time             `json:"-"`
+
+	// HelpfulCount — сколько пользователей отметили отзыв полезным
+	HelpfulCount int `json:"helpful_count" gorm:"not null;default:0"`
+
+	// Reply — ответ водителя; в публичных выдачах подгружается только опубликованный
+	Reply *ReviewReply `json:"reply,omitempty" gorm:"foreignKey:ReviewID"`
+}
+
+// Counted — учитывается ли отзыв в рейтингах
+func (r *Review) Counted() bool {
+	return !r.Sealed && r.Status == constants.ReviewPublished
+}
+
+// ReviewHelpfulVote — отметка «полезно» от пользователя, одна на отзыв
+type ReviewHelpfulVote struct {
+	ReviewID  uint      `json:"review_id" gorm:"primaryKey;autoIncrement:false"`
+	VoterID   uint      `json:"voter_id" gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewReport — жалоба пользователя на отзыв
+type ReviewReport struct {
+	Base
+
+	ReviewID   uint   `json:"review_id" gorm:"not null;uniqueIndex:idx_review_reports_reporter"`
+	ReporterID uint   `json:"reporter_id" gorm:"not null;uniqueIndex:idx_review_reports_reporter"`
+	Reason     string `json:"reason" gorm:"type:text;not null"`
+}
+
+// ReviewReply — публичный ответ водителя на отзыв, не больше одного на отзыв
+type ReviewReply struct {
+	Base
+
+	ReviewID uint   `json:"review_id" gorm:"not null;uniqueIndex"`
+	AuthorID uint   `json:"author_id" gorm:"not null;index"`
+	Text     string `json:"text" gorm:"type:text;not null"`
+
+	// модерация — по тем же правилам, что и у отзывов
+	Status           constants.ReviewStatus `json:"status" gorm:"type:varchar(30);not null;default:published;index"`
+	ModerationFlags  string                 `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	ModerationReason string                 `json:"-" gorm:"type:text;not null;default:''"`
+	ModeratedBy      *uint                  `json:"-"`
+	ModeratedAt      *time.Time             `json:"-"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// SavedSearch — сохранённый пассажиром поиск, по которому приходят уведомления о новых поездках
+type SavedSearch struct {
+	Base
+
+	UserID   uint       `json:"user_id" gorm:"not null;index"`
+	FromCity string     `json:"from_city" gorm:"type:varchar(100);not null;index"`
+	ToCity   string     `json:"to_city" gorm:"type:varchar(100);not null;index"`
+	DateFrom *time.Time `json:"date_from"`
+	DateTo   *time.Time `json:"date_to"`
+	Seats    int        `json:"seats" gorm:"not null;default:1;check:seats > 0"`
+	MaxPrice *int       `json:"max_price" gorm:"check:max_price >= 0"`
+	Active   bool       `json:"active" gorm:"not null;default:true;index"`
+}
+
+// SearchAlert — уведомление подписчику о найденной поездке.
+// Уникальность (user_id, trip_id) не даёт прислать одну поездку дважды.
+type SearchAlert struct {
+	Base
+
+	UserID        uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_search_alerts_user_trip"`
+	TripID        uint       `json:"trip_id" gorm:"not null;uniqueIndex:idx_search_alerts_user_trip"`
+	SavedSearchID uint       `json:"saved_search_id" gorm:"not null;index"`
+	SentAt        *time.Time `json:"sent_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// Session — вход пользователя с одного устройства. Все токены обновления сессии
+// образуют одно семейство: при повторном предъявлении старого токена закрывается вся сессия
+type Session struct {
+	Base
+
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	DeviceName string    `json:"device_name" gorm:"type:varchar(100);not null"`
+	IP         string    `json:"ip" gorm:"type:varchar(64);not null;default:''"`
+	UserAgent  string    `json:"user_agent" gorm:"type:varchar(255);not null;default:''"`
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"not null"`
+
+	// ExpiresAt продлевается при каждом обновлении токена
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+
+	RevokedAt    *time.Time                    `json:"revoked_at,omitempty"`
+	RevokeReason constants.SessionRevokeReason `json:"revoke_reason,omitempty" gorm:"type:varchar(20);not null;default:''"`
+}
+
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken — хеш токена обновления. Токен одноразовый: при обновлении
+// он помечается использованным и сессия получает новый
+type RefreshToken struct {
+	Base
+
+	SessionID uint       `json:"session_id" gorm:"not null;index"`
+	Hash      string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
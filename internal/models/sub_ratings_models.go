@@ -0,0 +1,48 @@
+package models
+
+import "github.com/mutsaevz/team-5-ambitious/internal/constants"
+
+// SubRatings — необязательные оценки водителя по категориям, каждая от 1 до 5.
+// Встраивается в Review с префиксом sub_, поэтому в проверках имена колонок с префиксом;
+// NULL (оценка не указана) проверку проходит.
+type SubRatings struct {
+	Punctuality   *int `json:"punctuality,omitempty" gorm:"check:sub_punctuality BETWEEN 1 AND 5"`
+	DrivingSafety *int `json:"driving_safety,omitempty" gorm:"check:sub_driving_safety BETWEEN 1 AND 5"`
+	CarComfort    *int `json:"car_comfort,omitempty" gorm:"check:sub_car_comfort BETWEEN 1 AND 5"`
+	Communication *int `json:"communication,omitempty" gorm:"check:sub_communication BETWEEN 1 AND 5"`
+}
+
+// Values возвращает только заполненные категории
+func (s SubRatings) Values() map[constants.ReviewCategory]int {
+	values := make(map[constants.ReviewCategory]int, 4)
+
+	for category, v := range map[constants.ReviewCategory]*int{
+		constants.CategoryPunctuality:   s.Punctuality,
+		constants.CategoryDrivingSafety: s.DrivingSafety,
+		constants.CategoryCarComfort:    s.CarComfort,
+		constants.CategoryCommunication: s.Communication,
+	} {
+		if v != nil {
+			values[category] = *v
+		}
+	}
+
+	return values
+}
+
+func (s SubRatings) IsEmpty() bool {
+	return len(s.Values()) == 0
+}
+
+func (s SubRatings) Equal(other SubRatings) bool {
+	a, b := s.Values(), other.Values()
+	if len(a) != len(b) {
+		return false
+	}
+	for category, v := range a {
+		if w, ok := b[category]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
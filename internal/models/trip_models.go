@@ -18,4 +18,19 @@ type Trip struct {
 	Price          int       `json:"price" gorm:"not null;check:price >= 0"`
 	TripStatus     string    `json:"trip_status" gorm:"type:varchar(50);not null;index"`
 	AvgRating      float64   `json:"avg_rating" gorm:"default:0.0;check:avg_rating >= 0 AND avg_rating <= 5"`
+
+	// DriverRating — средняя оценка водителя; заполняется только в поиске поездок
+	DriverRating float64 `json:"driver_rating" gorm:"->;-:migration"`
+	// DriverScore — ранжирующая оценка водителя для сортировки; наружу не отдаётся
+	DriverScore float64 `json:"-" gorm:"->;-:migration"`
+	// DriverVerified — бейдж проверенного водителя; заполняется только в поиске поездок
+	DriverVerified bool `json:"driver_verified" gorm:"->;-:migration"`
+
+	// Фактические время начала и окончания: ставит водитель или воркер по истечении льготного периода
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	Preferences TripPreferences `json:"preferences" gorm:"embedded;embeddedPrefix:pref_"`
+
+	Car *Car `json:"car,omitempty" gorm:"foreignKey:CarID"`
 }
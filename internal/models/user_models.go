@@ -1,9 +1,41 @@
 package models
 
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
 type User struct {
 	Base
 
 	Name    string `json:"name" gorm:"type:varchar(255);not null"`
 	Phone   string `json:"phone" gorm:"type:varchar(20);not null;unique;index"`
 	Balance int    `json:"balance" gorm:"not null;default:0;check:balance >= 0"`
+
+	Gender constants.Gender `json:"gender" gorm:"type:varchar(10);not null;default:''"`
+
+	Role constants.Role `json:"role" gorm:"type:varchar(20);not null;default:passenger;index"`
+
+	// PasswordHash — PBKDF2-хеш пароля; пустой у аккаунтов, созданных до входа по паролю
+	PasswordHash string `json:"-" gorm:"type:varchar(255);not null;default:''"`
+
+	UserProfile `gorm:"embedded"`
+
+	// Условия, которые подставляются в новые поездки водителя
+	DefaultPreferences TripPreferences `json:"default_preferences" gorm:"embedded;embeddedPrefix:default_pref_"`
+
+	// Бейдж проверенного водителя: ставится, когда администратор одобрил водительское удостоверение
+	DriverVerified   bool       `json:"driver_verified" gorm:"not null;default:false;index"`
+	DriverVerifiedAt *time.Time `json:"driver_verified_at,omitempty"`
+
+	// Ключи аватара в BlobStorage; наружу отдаются только подписанные ссылки
+	AvatarKey      string `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	AvatarThumbKey string `json:"-" gorm:"type:varchar(255);not null;default:''"`
+
+	// ErasedAt — аккаунт удалён по просьбе пользователя: имя и телефон обезличены
+	ErasedAt *time.Time `json:"erased_at,omitempty"`
 }
+
+// FormerUserName — имя обезличенного пользователя; под ним остаются его отзывы
+const FormerUserName = "Former user"
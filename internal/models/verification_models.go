@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// VerificationDocument — документ водителя, загруженный на проверку.
+// Сам файл лежит в BlobStorage и отдаётся только администратору по подписанной ссылке.
+type VerificationDocument struct {
+	Base
+
+	UserID uint                   `json:"user_id" gorm:"not null;index"`
+	CarID  *uint                  `json:"car_id,omitempty" gorm:"index"`
+	Type   constants.DocumentType `json:"type" gorm:"type:varchar(30);not null;index"`
+
+	// LicensePlate — номер автомобиля на момент загрузки СТС: при смене номера документ устаревает
+	LicensePlate string `json:"license_plate,omitempty" gorm:"type:varchar(20);not null;default:''"`
+
+	Key         string `json:"-" gorm:"type:varchar(255);not null"`
+	ContentType string `json:"content_type" gorm:"type:varchar(100);not null"`
+
+	Status       constants.VerificationStatus `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	RejectReason string                       `json:"reject_reason,omitempty" gorm:"type:text;not null;default:''"`
+	ReviewedBy   *uint                        `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time                   `json:"reviewed_at,omitempty"`
+}
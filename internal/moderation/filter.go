@@ -0,0 +1,155 @@
+package moderation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Reason — почему текст отправлен на ручную проверку
+type Reason string
+
+const (
+	ReasonBannedWord Reason = "banned_word"
+	ReasonPhone      Reason = "phone"
+	ReasonURL        Reason = "url"
+	ReasonSpam       Reason = "repeated_chars"
+)
+
+// Config — списки запрещённых слов. Слово с '*' на конце задаёт основу:
+// "дурак*" ловит и "дурака", и "дураки".
+type Config struct {
+	BannedRU []string
+	BannedEN []string
+
+	// MaxRepeat — сколько одинаковых символов подряд ещё допустимо
+	MaxRepeat int
+}
+
+// Filter — автоматическая проверка текста отзывов и ответов
+type Filter struct {
+	exact     map[string]struct{}
+	prefixes  []string
+	maxRepeat int
+}
+
+const defaultMaxRepeat = 5
+
+var (
+	urlPattern   = regexp.MustCompile(`(?i)(https?://|www\.|t\.me/)\S+|\b[a-z0-9][a-z0-9-]*\.(ru|com|net|org|io|me|su|info)\b|[^\s.]+\.рф`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-()]{8,}\d`)
+)
+
+func NewFilter(cfg Config) *Filter {
+	f := &Filter{
+		exact:     make(map[string]struct{}),
+		maxRepeat: cfg.MaxRepeat,
+	}
+
+	if f.maxRepeat <= 0 {
+		f.maxRepeat = defaultMaxRepeat
+	}
+
+	for _, list := range [][]string{cfg.BannedRU, cfg.BannedEN} {
+		for _, w := range list {
+			w = normalizeWord(strings.TrimSpace(w))
+			switch {
+			case w == "" || w == "*":
+			case strings.HasSuffix(w, "*"):
+				f.prefixes = append(f.prefixes, strings.TrimSuffix(w, "*"))
+			default:
+				f.exact[w] = struct{}{}
+			}
+		}
+	}
+
+	return f
+}
+
+// Check возвращает причины, по которым текст нельзя публиковать без модератора.
+// Пустой результат — текст чистый.
+func (f *Filter) Check(text string) []Reason {
+	var reasons []Reason
+
+	if f.hasBannedWord(text) {
+		reasons = append(reasons, ReasonBannedWord)
+	}
+
+	if hasPhone(text) {
+		reasons = append(reasons, ReasonPhone)
+	}
+
+	if urlPattern.MatchString(text) {
+		reasons = append(reasons, ReasonURL)
+	}
+
+	if longestRun(text) > f.maxRepeat {
+		reasons = append(reasons, ReasonSpam)
+	}
+
+	return reasons
+}
+
+func (f *Filter) hasBannedWord(text string) bool {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, w := range words {
+		w = normalizeWord(w)
+
+		if _, ok := f.exact[w]; ok {
+			return true
+		}
+
+		for _, p := range f.prefixes {
+			if strings.HasPrefix(w, p) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasPhone ищет последовательности, похожие на номер: не меньше 10 цифр
+// с допустимыми разделителями
+func hasPhone(text string) bool {
+	for _, m := range phonePattern.FindAllString(text, -1) {
+		digits := 0
+		for _, r := range m {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits >= 10 {
+			return true
+		}
+	}
+	return false
+}
+
+// longestRun — самая длинная серия одинаковых символов, без учёта пробелов
+func longestRun(text string) int {
+	longest, run := 0, 0
+	var prev rune
+
+	for i, r := range []rune(strings.ToLower(text)) {
+		if i > 0 && r == prev && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+
+		if run > longest {
+			longest = run
+		}
+	}
+
+	return longest
+}
+
+func normalizeWord(w string) string {
+	return strings.ReplaceAll(strings.ToLower(w), "ё", "е")
+}
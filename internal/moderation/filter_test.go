@@ -0,0 +1,77 @@
+package moderation
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterCheck(t *testing.T) {
+	filter := NewFilter(Config{
+		BannedRU:  []string{" дурак* ", "ёлка", "", "*"},
+		BannedEN:  []string{"idiot", "scam*"},
+		MaxRepeat: 3,
+	})
+
+	tests := []struct {
+		name string
+		text string
+		want []Reason
+	}{
+		{"clean", "Отличная поездка, водитель вежливый", nil},
+		{"empty", "", nil},
+		{"exact word", "You idiot!", []Reason{ReasonBannedWord}},
+		{"exact word is case insensitive", "IDIOT", []Reason{ReasonBannedWord}},
+		{"exact word does not match a longer word", "idiotic driving", nil},
+		{"stem matches inflection", "Водитель дураки", []Reason{ReasonBannedWord}},
+		{"english stem", "total scammer", []Reason{ReasonBannedWord}},
+		{"ё and е are the same letter", "елка", []Reason{ReasonBannedWord}},
+		{"empty and bare star entries are ignored", "звёздочка * ничего", nil},
+		{"phone with separators", "звоните +7 (999) 123-45-67", []Reason{ReasonPhone}},
+		{"ten digits", "9991234567", []Reason{ReasonPhone}},
+		{"nine digits are not a phone", "999 123 456", nil},
+		{"url with scheme", "see https://example.org/page", []Reason{ReasonURL}},
+		{"bare domain", "пишите на example.com", []Reason{ReasonURL}},
+		{"telegram", "t.me/driver", []Reason{ReasonURL}},
+		{"cyrillic domain", "сайт.рф", []Reason{ReasonURL}},
+		{"repeat at limit", "ааа", nil},
+		{"repeat over limit", "супеееер", []Reason{ReasonSpam}},
+		{"repeat ignores case", "AaAa", []Reason{ReasonSpam}},
+		{"spaces do not count as a run", "a     b", nil},
+		{
+			"several reasons in order",
+			"idiot!!!! call 89991234567 or www.spam.ru",
+			[]Reason{ReasonBannedWord, ReasonPhone, ReasonURL, ReasonSpam},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Check(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Check(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFilterMaxRepeatDefault(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxRepeat int
+		text      string
+		want      bool
+	}{
+		{"zero uses default, at limit", 0, "ооооол", false},
+		{"zero uses default, over limit", 0, "оооооол", true},
+		{"negative uses default", -1, "оооооол", true},
+		{"explicit limit", 1, "oo", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Contains(NewFilter(Config{MaxRepeat: tt.maxRepeat}).Check(tt.text), ReasonSpam)
+			if got != tt.want {
+				t.Errorf("spam = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
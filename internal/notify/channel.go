@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+var ErrChannelDisabled = errors.New("notification channel is disabled")
+
+// SMSSender отправляет SMS на номер в формате E.164
+type SMSSender interface {
+	SendSMS(ctx context.Context, phone, text string) error
+}
+
+// EmailSender отправляет письмо
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+// PushSender отправляет push на все устройства пользователя
+type PushSender interface {
+	SendPush(ctx context.Context, userID uint, title, body string) error
+}
+
+// Message — готовое к отправке уведомление
+type Message struct {
+	Channel   constants.NotificationChannel
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Channels — подключённые способы доставки; nil означает, что канал выключен
+type Channels struct {
+	SMS   SMSSender
+	Email EmailSender
+	Push  PushSender
+}
+
+// Enabled — включён ли канал
+func (c Channels) Enabled(channel constants.NotificationChannel) bool {
+	switch channel {
+	case constants.ChannelSMS:
+		return c.SMS != nil
+	case constants.ChannelEmail:
+		return c.Email != nil
+	case constants.ChannelPush:
+		return c.Push != nil
+	}
+	return false
+}
+
+// Send передаёт сообщение в нужный канал
+func (c Channels) Send(ctx context.Context, msg Message) error {
+	if !c.Enabled(msg.Channel) {
+		return ErrChannelDisabled
+	}
+
+	switch msg.Channel {
+	case constants.ChannelSMS:
+		return c.SMS.SendSMS(ctx, msg.Recipient, msg.Body)
+
+	case constants.ChannelEmail:
+		return c.Email.SendEmail(ctx, msg.Recipient, msg.Subject, msg.Body)
+
+	default:
+		userID, err := strconv.ParseUint(msg.Recipient, 10, 64)
+		if err != nil {
+			return err
+		}
+		return c.Push.SendPush(ctx, uint(userID), msg.Subject, msg.Body)
+	}
+}
+
+// Delivery — куда отправить уведомление пользователю
+type Delivery struct {
+	Channel   constants.NotificationChannel
+	Recipient string
+}
+
+// Route выбирает каналы для пользователя среди включённых: push всегда,
+// SMS — если пользователь предпочитает связь по телефону или SMS.
+// Email пока не выбирается: адреса почты у пользователей нет
+func Route(user *models.User, channels Channels) []Delivery {
+	var result []Delivery
+
+	if channels.Enabled(constants.ChannelPush) {
+		result = append(result, Delivery{
+			Channel:   constants.ChannelPush,
+			Recipient: strconv.FormatUint(uint64(user.ID), 10),
+		})
+	}
+
+	wantsSMS := user.PreferredContact == constants.ContactSMS || user.PreferredContact == constants.ContactPhone
+	if wantsSMS && user.Phone != "" && channels.Enabled(constants.ChannelSMS) {
+		result = append(result, Delivery{
+			Channel:   constants.ChannelSMS,
+			Recipient: user.Phone,
+		})
+	}
+
+	return result
+}
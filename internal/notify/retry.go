@@ -0,0 +1,27 @@
+package notify
+
+import "time"
+
+const (
+	DefaultMaxAttempts = 6
+	DefaultBaseDelay   = 30 * time.Second
+	DefaultMaxDelay    = time.Hour
+)
+
+// Retry — правила повторной отправки: задержка удваивается с каждой попыткой
+type Retry struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff — задержка перед следующей попыткой после attempts неудачных
+func (r Retry) Backoff(attempts int) time.Duration {
+	delay := r.BaseDelay
+
+	for i := 1; i < attempts && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, r.MaxDelay)
+}
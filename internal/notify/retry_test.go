@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	defaults := Retry{MaxAttempts: DefaultMaxAttempts, BaseDelay: DefaultBaseDelay, MaxDelay: DefaultMaxDelay}
+
+	tests := []struct {
+		name     string
+		retry    Retry
+		attempts int
+		want     time.Duration
+	}{
+		{"zero attempts", defaults, 0, 30 * time.Second},
+		{"first failure", defaults, 1, 30 * time.Second},
+		{"second failure doubles", defaults, 2, time.Minute},
+		{"fifth failure", defaults, 5, 8 * time.Minute},
+		{"seventh failure", defaults, 7, 32 * time.Minute},
+		{"capped at max delay", defaults, 8, time.Hour},
+		{"huge attempts do not overflow", defaults, 1000, time.Hour},
+		{"lands exactly on max", Retry{BaseDelay: time.Second, MaxDelay: 4 * time.Second}, 3, 4 * time.Second},
+		{"base above max is capped", Retry{BaseDelay: 2 * time.Hour, MaxDelay: time.Hour}, 1, time.Hour},
+		{"zero base stays zero", Retry{MaxDelay: time.Hour}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.retry.Backoff(tt.attempts); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LogSender — заглушка для разработки: пишет уведомления в лог вместо отправки.
+// Подходит для любого канала
+type LogSender struct {
+	logger *slog.Logger
+}
+
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) SendSMS(_ context.Context, phone, text string) error {
+	s.logger.Info("sms", slog.String("to", phone), slog.String("text", text))
+	return nil
+}
+
+func (s *LogSender) SendEmail(_ context.Context, to, subject, body string) error {
+	s.logger.Info("email", slog.String("to", to), slog.String("subject", subject), slog.String("body", body))
+	return nil
+}
+
+func (s *LogSender) SendPush(_ context.Context, userID uint, title, body string) error {
+	s.logger.Info("push", slog.Uint64("user_id", uint64(userID)), slog.String("title", title), slog.String("body", body))
+	return nil
+}
+
+// FileSender — заглушка, которая дописывает уведомления JSON-строками в файл,
+// чтобы их можно было проверить в тестовом окружении
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) (*FileSender, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSender{path: path}, nil
+}
+
+type fileRecord struct {
+	At      time.Time `json:"at"`
+	Channel string    `json:"channel"`
+	To      string    `json:"to,omitempty"`
+	UserID  uint      `json:"user_id,omitempty"`
+	Subject string    `json:"subject,omitempty"`
+	Body    string    `json:"body"`
+}
+
+func (s *FileSender) SendSMS(_ context.Context, phone, text string) error {
+	return s.write(fileRecord{Channel: "sms", To: phone, Body: text})
+}
+
+func (s *FileSender) SendEmail(_ context.Context, to, subject, body string) error {
+	return s.write(fileRecord{Channel: "email", To: to, Subject: subject, Body: body})
+}
+
+func (s *FileSender) SendPush(_ context.Context, userID uint, title, body string) error {
+	return s.write(fileRecord{Channel: "push", UserID: userID, Subject: title, Body: body})
+}
+
+func (s *FileSender) write(record fileRecord) error {
+	record.At = time.Now().UTC()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
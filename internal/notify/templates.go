@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+
+	// DefaultLocale — язык уведомлений, если у пользователя нет ни русского, ни английского
+	DefaultLocale = LocaleRU
+)
+
+// Data — подстановки для шаблонов уведомлений о заявках и поездках
+type Data struct {
+	BookingID     uint
+	TripID        uint
+	FromCity      string
+	ToCity        string
+	StartTime     time.Time
+	Seats         int
+	PassengerName string
+	Price         int
+}
+
+// TripData заполняет подстановки по заявке и поездке
+func TripData(booking *models.Booking, trip *models.Trip) Data {
+	return Data{
+		BookingID: booking.ID,
+		TripID:    trip.ID,
+		FromCity:  trip.FromCity,
+		ToCity:    trip.ToCity,
+		StartTime: trip.StartTime,
+		Seats:     booking.Seats,
+	}
+}
+
+// MatchData заполняет подстановки для уведомления о поездке по сохранённому поиску
+func MatchData(trip *models.Trip) Data {
+	return Data{
+		TripID:    trip.ID,
+		FromCity:  trip.FromCity,
+		ToCity:    trip.ToCity,
+		StartTime: trip.StartTime,
+		Seats:     trip.AvailableSeats,
+		Price:     trip.Price,
+	}
+}
+
+type text struct {
+	subject string
+	body    string
+}
+
+var catalog = map[constants.NotificationKind]map[string]text{
+	constants.NotificationBookingCreated: {
+		LocaleRU: {
+			subject: "Новая заявка на поездку",
+			body:    "{{.PassengerName}} хочет поехать с вами {{.FromCity}} → {{.ToCity}} {{date .StartTime}}, мест: {{.Seats}}. Одобрите или отклоните заявку.",
+		},
+		LocaleEN: {
+			subject: "New booking request",
+			body:    "{{.PassengerName}} wants to join your trip {{.FromCity}} → {{.ToCity}} on {{date .StartTime}}, seats: {{.Seats}}. Please approve or reject the request.",
+		},
+	},
+	constants.NotificationBookingApproved: {
+		LocaleRU: {
+			subject: "Заявка одобрена",
+			body:    "Водитель одобрил вашу заявку на поездку {{.FromCity}} → {{.ToCity}} {{date .StartTime}}, мест: {{.Seats}}.",
+		},
+		LocaleEN: {
+			subject: "Booking approved",
+			body:    "The driver approved your booking for {{.FromCity}} → {{.ToCity}} on {{date .StartTime}}, seats: {{.Seats}}.",
+		},
+	},
+	constants.NotificationBookingRejected: {
+		LocaleRU: {
+			subject: "Заявка отклонена",
+			body:    "Водитель отклонил вашу заявку на поездку {{.FromCity}} → {{.ToCity}} {{date .StartTime}}. Попробуйте найти другую поездку.",
+		},
+		LocaleEN: {
+			subject: "Booking rejected",
+			body:    "The driver rejected your booking for {{.FromCity}} → {{.ToCity}} on {{date .StartTime}}. Try looking for another trip.",
+		},
+	},
+	constants.NotificationBookingCancelled: {
+		LocaleRU: {
+			subject: "Пассажир отменил заявку",
+			body:    "{{.PassengerName}} отменил(а) заявку на поездку {{.FromCity}} → {{.ToCity}} {{date .StartTime}}, мест: {{.Seats}}.",
+		},
+		LocaleEN: {
+			subject: "Booking cancelled",
+			body:    "{{.PassengerName}} cancelled their booking for {{.FromCity}} → {{.ToCity}} on {{date .StartTime}}, seats: {{.Seats}}.",
+		},
+	},
+}
+
+type compiled struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("02.01.2006 15:04") },
+}
+
+// templates разбираются при старте: ошибка в шаблоне должна ронять сервис сразу, а не при отправке
+var templates = compile()
+
+func compile() map[constants.NotificationKind]map[string]compiled {
+	result := make(map[constants.NotificationKind]map[string]compiled, len(catalog))
+
+	for kind, locales := range catalog {
+		result[kind] = make(map[string]compiled, len(locales))
+
+		for locale, t := range locales {
+			name := string(kind) + "." + locale
+			result[kind][locale] = compiled{
+				subject: template.Must(template.New(name + ".subject").Funcs(funcs).Parse(t.subject)),
+				body:    template.Must(template.New(name + ".body").Funcs(funcs).Parse(t.body)),
+			}
+		}
+	}
+
+	return result
+}
+
+// Render возвращает тему и текст уведомления на нужном языке
+func Render(kind constants.NotificationKind, locale string, data Data) (string, string, error) {
+	locales, ok := templates[kind]
+	if !ok {
+		return "", "", fmt.Errorf("notify: unknown notification kind %q", kind)
+	}
+
+	t, ok := locales[locale]
+	if !ok {
+		t = locales[DefaultLocale]
+	}
+
+	var subject, body bytes.Buffer
+
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+
+	return subject.String(), body.String(), nil
+}
+
+// Locale выбирает язык уведомлений по языкам из профиля: первый поддерживаемый
+func Locale(languages models.Languages) string {
+	for _, lang := range languages {
+		if lang == LocaleRU || lang == LocaleEN {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Codec превращает позицию keyset-пагинации в непрозрачную строку и обратно.
+// Токен подписан HMAC и привязан к scope, чтобы курсор одного списка нельзя
+// было подделать или подсунуть другому.
+type Codec struct {
+	secret []byte
+}
+
+func NewCodec(secret []byte) (*Codec, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("pagination: empty cursor secret")
+	}
+
+	return &Codec{secret: secret}, nil
+}
+
+type payload struct {
+	Score float64 `json:"s,omitempty"`
+	Key   int64   `json:"k"`
+	ID    uint    `json:"i"`
+	AsOf  int64   `json:"a,omitempty"`
+}
+
+func (c *Codec) Encode(scope string, cursor models.Cursor) string {
+	p := payload{
+		Score: cursor.Score,
+		Key:   cursor.Key.UnixNano(),
+		ID:    cursor.ID,
+	}
+	if !cursor.AsOf.IsZero() {
+		p.AsOf = cursor.AsOf.UnixNano()
+	}
+
+	body, _ := json.Marshal(p)
+
+	data := base64.RawURLEncoding.EncodeToString(body)
+
+	return data + "." + c.sign(scope, data)
+}
+
+func (c *Codec) Decode(scope, token string) (*models.Cursor, error) {
+	data, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(c.sign(scope, data))) {
+		return nil, ErrInvalidCursor
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &models.Cursor{
+		Score: p.Score,
+		Key:   time.Unix(0, p.Key).UTC(),
+		ID:    p.ID,
+	}
+	if p.AsOf != 0 {
+		cursor.AsOf = time.Unix(0, p.AsOf).UTC()
+	}
+
+	return cursor, nil
+}
+
+func (c *Codec) sign(scope, data string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(data))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+func newTestCodec(t *testing.T, secret string) *Codec {
+	t.Helper()
+
+	codec, err := NewCodec([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codec
+}
+
+func TestNewCodecRejectsEmptySecret(t *testing.T) {
+	if _, err := NewCodec(nil); err == nil {
+		t.Fatal("expected an error for an empty secret")
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	key := time.Date(2026, 3, 1, 12, 30, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor models.Cursor
+	}{
+		{"key and id", models.Cursor{Key: key, ID: 42}},
+		{"negative score", models.Cursor{Score: -4.75, Key: key, ID: 7}},
+		{"as of", models.Cursor{Score: 3.5, Key: key, ID: 1, AsOf: key.Add(time.Hour)}},
+		{"zero key", models.Cursor{Key: time.Unix(0, 0).UTC(), ID: 0}},
+	}
+
+	codec := newTestCodec(t, "secret")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.Decode("trips", codec.Encode("trips", tt.cursor))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if got.Score != tt.cursor.Score || got.ID != tt.cursor.ID ||
+				!got.Key.Equal(tt.cursor.Key) || !got.AsOf.Equal(tt.cursor.AsOf) {
+				t.Errorf("got %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestCodecRejectsTampering(t *testing.T) {
+	codec := newTestCodec(t, "secret")
+	token := codec.Encode("trips", models.Cursor{Key: time.Now(), ID: 10})
+	data, signature, _ := strings.Cut(token, ".")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"k":0,"i":1}`))
+
+	tests := []struct {
+		name  string
+		codec *Codec
+		scope string
+		token string
+	}{
+		{"empty", codec, "trips", ""},
+		{"no signature", codec, "trips", data},
+		{"empty signature", codec, "trips", data + "."},
+		{"other scope", codec, "bookings", token},
+		{"other secret", newTestCodec(t, "another"), "trips", token},
+		{"payload replaced", codec, "trips", forged + "." + signature},
+		{"signature truncated", codec, "trips", data + "." + signature[:len(signature)-1]},
+		{"extra dot", codec, "trips", token + ".x"},
+		{"signed garbage", codec, "trips", "!!!." + codec.sign("trips", "!!!")},
+		{"signed non-json", codec, "trips", "bm90LWpzb24." + codec.sign("trips", "bm90LWpzb24")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.codec.Decode(tt.scope, tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
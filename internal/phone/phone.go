@@ -0,0 +1,50 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid phone number")
+
+// DefaultCountryCode подставляется в номера, записанные без кода страны
+const DefaultCountryCode = "7"
+
+// Normalize приводит номер к E.164 (+79991234567): убирает пробелы, скобки, дефисы и точки,
+// понимает международный префикс 00 и местные форматы 8XXXXXXXXXX и XXXXXXXXXX
+func Normalize(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	international := strings.HasPrefix(s, "+")
+	s = strings.TrimPrefix(s, "+")
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ', r == '-', r == '(', r == ')', r == '.':
+		default:
+			return "", ErrInvalid
+		}
+	}
+
+	digits := b.String()
+
+	if !international {
+		switch {
+		case strings.HasPrefix(digits, "00"):
+			digits = digits[2:]
+		case len(digits) == 11 && digits[0] == '8':
+			digits = DefaultCountryCode + digits[1:]
+		case len(digits) == 10:
+			digits = DefaultCountryCode + digits
+		}
+	}
+
+	// E.164: до 15 цифр, код страны не начинается с нуля
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalid
+	}
+
+	return "+" + digits, nil
+}
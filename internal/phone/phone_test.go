@@ -0,0 +1,55 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"already e164", "+79991234567", "+79991234567", false},
+		{"formatted international", " +7 (999) 123-45-67 ", "+79991234567", false},
+		{"dots as separators", "+7.999.123.45.67", "+79991234567", false},
+		{"local with 8", "8 999 123 45 67", "+79991234567", false},
+		{"local ten digits", "9991234567", "+79991234567", false},
+		{"double zero prefix", "00 44 20 7946 0958", "+442079460958", false},
+		{"foreign international", "+1 415 555 2671", "+14155552671", false},
+		{"plus disables local rules", "+8 999 123 45 67", "+89991234567", false},
+		{"eleven digits not starting with 8", "79991234567", "+79991234567", false},
+		{"minimum length", "+12345678", "+12345678", false},
+		{"maximum length", "+123456789012345", "+123456789012345", false},
+		{"too short", "+1234567", "", true},
+		{"too long", "+1234567890123456", "", true},
+		{"country code starting with zero", "+0123456789", "", true},
+		{"only double zero prefix", "00", "", true},
+		{"empty", "", "", true},
+		{"letters", "+7 999 CALL-NOW", "", true},
+		{"plus in the middle", "7+9991234567", "", true},
+		{"double plus", "++79991234567", "", true},
+		{"extension", "+79991234567 ext 1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("Normalize(%q) err = %v, want %v", tt.raw, err, ErrInvalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q) unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/auth"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var ErrForbidden = errors.New("access denied")
+
+// Policy решает, может ли пользователь выполнить действие над чужим или своим объектом.
+// Проверки владения читают объект из базы; отсутствующий объект — repository.ErrNotFound.
+type Policy struct {
+	trips    repository.TripRepository
+	bookings repository.BookingRepository
+	cars     repository.CarRepository
+}
+
+func New(trips repository.TripRepository, bookings repository.BookingRepository, cars repository.CarRepository) *Policy {
+	return &Policy{
+		trips:    trips,
+		bookings: bookings,
+		cars:     cars,
+	}
+}
+
+// Self — действовать от имени userID может только он сам
+func Self(p *auth.Principal, userID uint) error {
+	if p.UserID != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// SelfOrStaff — свои данные видит пользователь, чужие — поддержка и администраторы
+func SelfOrStaff(p *auth.Principal, userID uint) error {
+	if p.UserID != userID && !p.IsStaff() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func HasRole(p *auth.Principal, roles ...constants.Role) error {
+	if !slices.Contains(roles, p.Role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// ManageTrip — менять поездку, начинать и завершать её может только её водитель
+func (pl *Policy) ManageTrip(p *auth.Principal, tripID uint) error {
+	trip, err := pl.trips.GetByID(tripID)
+	if err != nil {
+		return err
+	}
+	return Self(p, trip.DriverID)
+}
+
+// ManageCar — автомобиль меняет только владелец
+func (pl *Policy) ManageCar(p *auth.Principal, carID uint) error {
+	car, err := pl.cars.GetByID(carID)
+	if err != nil {
+		return err
+	}
+	return Self(p, car.OwnerID)
+}
+
+// CancelBooking — отменить заявку может только пассажир, который её оставил
+func (pl *Policy) CancelBooking(p *auth.Principal, bookingID uint) error {
+	booking, err := pl.bookings.GetByID(bookingID)
+	if err != nil {
+		return err
+	}
+	return Self(p, booking.PassengerID)
+}
+
+// DecideBooking — одобрить или отклонить заявку может только водитель поездки
+func (pl *Policy) DecideBooking(p *auth.Principal, bookingID uint) error {
+	booking, err := pl.bookings.GetByID(bookingID)
+	if err != nil {
+		return err
+	}
+	return pl.ManageTrip(p, booking.TripID)
+}
+
+// ViewBooking — заявку видят пассажир, водитель поездки и сотрудники
+func (pl *Policy) ViewBooking(p *auth.Principal, bookingID uint) error {
+	booking, err := pl.bookings.GetByID(bookingID)
+	if err != nil {
+		return err
+	}
+
+	if p.UserID == booking.PassengerID || p.IsStaff() {
+		return nil
+	}
+
+	return pl.ManageTrip(p, booking.TripID)
+}
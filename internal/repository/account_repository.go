@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+// AccountRepository — операции удаления аккаунта, которые затрагивают сразу несколько таблиц
+type AccountRepository interface {
+	// HasTripInProgress — ведёт ли пользователь поездку или едет в ней пассажиром
+	HasTripInProgress(userID uint) (bool, error)
+
+	// BlobKeys — ключи всех файлов пользователя в хранилище: аватар, фото автомобилей, документы, выгрузки
+	BlobKeys(userID uint) ([]string, error)
+
+	// DeletePersonalData удаляет записи, которые нужны только самому пользователю:
+	// сохранённые поиски, блокировки, документы, фото, сессии и выгрузки
+	DeletePersonalData(userID uint) error
+
+	// Anonymize обезличивает пользователя и его автомобили и помечает аккаунт удалённым.
+	// Поездки, заявки и отзывы остаются: они нужны другим пользователям и рейтингам
+	Anonymize(userID uint, at time.Time) error
+
+	CreateErasure(erasure *models.AccountErasure) error
+
+	WithDB(db *gorm.DB) AccountRepository
+}
+
+type gormAccountRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAccountRepository(db *gorm.DB, logger *slog.Logger) AccountRepository {
+	return &gormAccountRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormAccountRepository) HasTripInProgress(userID uint) (bool, error) {
+	op := "repository.account.has_trip_in_progress"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var exists bool
+
+	err := r.db.Raw(`SELECT
+		EXISTS (SELECT 1 FROM trips
+			WHERE driver_id = ? AND trip_status = ? AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM bookings JOIN trips ON trips.id = bookings.trip_id
+			WHERE bookings.passenger_id = ? AND bookings.booking_status = ?
+			AND trips.trip_status = ? AND bookings.deleted_at IS NULL AND trips.deleted_at IS NULL)`,
+		userID, constants.TripInProgress,
+		userID, constants.BookingApproved, constants.TripInProgress,
+	).Scan(&exists).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *gormAccountRepository) BlobKeys(userID uint) ([]string, error) {
+	op := "repository.account.blob_keys"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var keys []string
+
+	err := r.db.Raw(`SELECT key FROM (
+		SELECT avatar_key AS key FROM users WHERE id = ?
+		UNION ALL SELECT avatar_thumb_key FROM users WHERE id = ?
+		UNION ALL SELECT car_photos.key FROM car_photos JOIN cars ON cars.id = car_photos.car_id
+			WHERE cars.owner_id = ? AND car_photos.deleted_at IS NULL
+		UNION ALL SELECT car_photos.thumb_key FROM car_photos JOIN cars ON cars.id = car_photos.car_id
+			WHERE cars.owner_id = ? AND car_photos.deleted_at IS NULL
+		UNION ALL SELECT key FROM verification_documents WHERE user_id = ? AND deleted_at IS NULL
+		UNION ALL SELECT key FROM data_exports WHERE user_id = ? AND deleted_at IS NULL
+	) AS blobs WHERE key <> ''`,
+		userID, userID, userID, userID, userID, userID,
+	).Scan(&keys).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *gormAccountRepository) DeletePersonalData(userID uint) error {
+	op := "repository.account.delete_personal_data"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	// удаляем насовсем: мягкое удаление оставило бы данные в базе.
+	// Session — чтобы условия шагов не накапливались в общем запросе
+	db := r.db.Unscoped().Session(&gorm.Session{})
+
+	steps := []struct {
+		name  string
+		query *gorm.DB
+		model any
+	}{
+		{"search_alerts", db.Where("user_id = ?", userID), &models.SearchAlert{}},
+		{"saved_searches", db.Where("user_id = ?", userID), &models.SavedSearch{}},
+		{"user_blocks", db.Where("blocker_id = ? OR blocked_id = ?", userID, userID), &models.UserBlock{}},
+		{"verification_documents", db.Where("user_id = ?", userID), &models.VerificationDocument{}},
+		{"car_photos", db.Where("car_id IN (?)", db.Model(&models.Car{}).Select("id").Where("owner_id = ?", userID)), &models.CarPhoto{}},
+		{"refresh_tokens", db.Where("session_id IN (?)", db.Model(&models.Session{}).Select("id").Where("user_id = ?", userID)), &models.RefreshToken{}},
+		{"sessions", db.Where("user_id = ?", userID), &models.Session{}},
+		{"data_exports", db.Where("user_id = ?", userID), &models.DataExport{}},
+		// в outbox лежат телефон и тексты с именами и маршрутами; неотправленные ушли бы на старый номер
+		{"notifications", db.Where("user_id = ?", userID), &models.Notification{}},
+	}
+
+	for _, step := range steps {
+		if err := step.query.Delete(step.model).Error; err != nil {
+			r.logger.Error("db error", slog.String("op", op), slog.String("table", step.name), slog.Any("error", err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *gormAccountRepository) Anonymize(userID uint, at time.Time) error {
+	op := "repository.account.anonymize"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"name":               models.FormerUserName,
+		"phone":              fmt.Sprintf("erased-%d", userID), // телефон уникален и обязателен
+		"balance":            0,
+		"gender":             "",
+		"role":               constants.RolePassenger,
+		"password_hash":      "",
+		"bio":                "",
+		"birth_year":         nil,
+		"languages":          "",
+		"preferred_contact":  "",
+		"driver_verified":    false,
+		"driver_verified_at": nil,
+		"avatar_key":         "",
+		"avatar_thumb_key":   "",
+		"erased_at":          at,
+	}).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	// поездки ссылаются на автомобили, поэтому их не удаляем насовсем, а убираем номер
+	err = r.db.Model(&models.Car{}).Where("owner_id = ?", userID).Updates(map[string]any{
+		"license_plate": "",
+		"verified":      false,
+		"verified_at":   nil,
+	}).Error
+	if err == nil {
+		err = r.db.Where("owner_id = ?", userID).Delete(&models.Car{}).Error
+	}
+	if err == nil {
+		err = r.db.Delete(&models.User{}, userID).Error
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormAccountRepository) CreateErasure(erasure *models.AccountErasure) error {
+	op := "repository.account.create_erasure"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(erasure.UserID)))
+
+	if err := r.db.Create(erasure).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormAccountRepository) WithDB(db *gorm.DB) AccountRepository {
+	return &gormAccountRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
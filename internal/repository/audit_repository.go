@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	Create(entry *models.AuditEntry) error
+
+	List(filter dto.AuditFilter) ([]models.AuditEntry, models.PageInfo, error)
+}
+
+type gormAuditRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAuditRepository(db *gorm.DB, logger *slog.Logger) AuditRepository {
+	return &gormAuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormAuditRepository) Create(entry *models.AuditEntry) error {
+	op := "repository.audit.create"
+	r.logger.Debug("db call", slog.String("op", op), slog.String("action", entry.Action))
+
+	if err := r.db.Create(entry).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormAuditRepository) List(filter dto.AuditFilter) ([]models.AuditEntry, models.PageInfo, error) {
+	op := "repository.audit.list"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	query := r.db.Model(&models.AuditEntry{})
+
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+
+	var entries []models.AuditEntry
+
+	// свежие записи сверху
+	query, info, err := applyPage(query, models.Page{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		After:    filter.After,
+	}, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+		desc:      true,
+	}, defaultPageSize)
+
+	if err == nil {
+		err = query.Find(&entries).Error
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	entries, info = trimPage(entries, info, func(e models.AuditEntry) models.Cursor { return e.CreatedCursor() })
+
+	return entries, info, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockRepository interface {
+	// Create — false, если блокировка уже есть
+	Create(blockerID, blockedID uint) (bool, error)
+
+	// Delete — false, если блокировки не было
+	Delete(blockerID, blockedID uint) (bool, error)
+
+	ListByBlocker(blockerID uint) ([]models.UserBlock, error)
+
+	// IsBlocked — заблокировал ли кто-то из пары другого
+	IsBlocked(a, b uint) (bool, error)
+
+	WithDB(db *gorm.DB) BlockRepository
+}
+
+type gormBlockRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewBlockRepository(db *gorm.DB, logger *slog.Logger) BlockRepository {
+	return &gormBlockRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// blockedPairExpr — условие «водитель поездки и пользователь ? блокируют друг друга»; параметр передаётся дважды
+const blockedPairExpr = "EXISTS (SELECT 1 FROM user_blocks WHERE (user_blocks.blocker_id = ? AND user_blocks.blocked_id = trips.driver_id) OR (user_blocks.blocker_id = trips.driver_id AND user_blocks.blocked_id = ?))"
+
+func (r *gormBlockRepository) Create(blockerID, blockedID uint) (bool, error) {
+	op := "repository.block.create"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("blocker_id", uint64(blockerID)),
+		slog.Uint64("blocked_id", uint64(blockedID)),
+	)
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserBlock{BlockerID: blockerID, BlockedID: blockedID})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormBlockRepository) Delete(blockerID, blockedID uint) (bool, error) {
+	op := "repository.block.delete"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("blocker_id", uint64(blockerID)),
+		slog.Uint64("blocked_id", uint64(blockedID)),
+	)
+
+	result := r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormBlockRepository) ListByBlocker(blockerID uint) ([]models.UserBlock, error) {
+	op := "repository.block.list_by_blocker"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("blocker_id", uint64(blockerID)))
+
+	var blocks []models.UserBlock
+	if err := r.db.Where("blocker_id = ?", blockerID).Order("created_at DESC").Find(&blocks).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (r *gormBlockRepository) IsBlocked(a, b uint) (bool, error) {
+	op := "repository.block.is_blocked"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("a", uint64(a)), slog.Uint64("b", uint64(b)))
+
+	var count int64
+	if err := r.db.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormBlockRepository) WithDB(db *gorm.DB) BlockRepository {
+	return &gormBlockRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
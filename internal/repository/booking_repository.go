@@ -1,20 +1,25 @@
 package repository
 
 import (
+	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookingRepository interface {
 	Create(booking *models.Booking) error
 
-	List(filter models.Page) ([]models.Booking, error)
+	List(filter models.Page) ([]models.Booking, models.PageInfo, error)
 
 	GetByID(id uint) (*models.Booking, error)
 
-	GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error)
+	GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.PendingBookingRow, error)
 
 	Exists(tripID uint, passengerID uint) (bool, error)
 
@@ -22,6 +27,36 @@ type BookingRepository interface {
 
 	Delete(id uint) error
 
+	CountsByTrips(tripIDs []uint) (map[uint]models.BookingCounts, error)
+
+	ListManifest(tripID uint) ([]models.ManifestRow, error)
+
+	ListByPassenger(filter dto.PassengerBookingFilter) ([]models.PassengerBookingRow, models.PageInfo, error)
+
+	// RejectPendingByTrip отклоняет ожидающие заявки поездки и возвращает их
+	RejectPendingByTrip(tripID uint) ([]models.Booking, error)
+
+	// ListActiveBetween — ожидающие и одобренные заявки на ещё не начавшиеся поездки,
+	// где один из пары пассажир, а другой водитель
+	ListActiveBetween(a, b uint, now time.Time) ([]models.Booking, error)
+
+	// ListActiveByPassenger — ожидающие и одобренные заявки пассажира на ещё не начавшиеся поездки
+	ListActiveByPassenger(passengerID uint, now time.Time) ([]models.Booking, error)
+
+	// CancelActiveByTrip отменяет ожидающие и одобренные заявки поездки и возвращает их
+	CancelActiveByTrip(tripID uint) ([]models.Booking, error)
+
+	// GetForReview возвращает заявку пассажира на поездку, по которой решается, можно ли
+	// оставить отзыв: одобренная важнее остальных, среди прочих — последняя
+	GetForReview(tripID, passengerID uint) (*models.Booking, error)
+
+	HasCheckIns(tripID uint) (bool, error)
+
+	// CheckIn и MarkNoShow срабатывают только для одобренной заявки без отметки о посадке
+	CheckIn(bookingID uint, now time.Time) (bool, error)
+
+	MarkNoShow(bookingID uint) (bool, error)
+
 	WithDB(db *gorm.DB) BookingRepository
 }
 
@@ -54,7 +89,7 @@ func (r *gormBookingRepository) Create(booking *models.Booking) error {
 	return nil
 }
 
-func (r *gormBookingRepository) List(filter models.Page) ([]models.Booking, error) {
+func (r *gormBookingRepository) List(filter models.Page) ([]models.Booking, models.PageInfo, error) {
 
 	op := "repository.booking.list"
 
@@ -62,25 +97,24 @@ func (r *gormBookingRepository) List(filter models.Page) ([]models.Booking, erro
 
 	var bookings []models.Booking
 
-	page := filter.Page
-	pageSize := filter.PageSize
-
-	if page < 1 {
-		page = 1
-	}
+	query, info, err := applyPage(r.DB.Model(&models.Booking{}), filter, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+		desc:      true,
+	}, defaultPageSize)
 
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 100
+	if err == nil {
+		err = query.Find(&bookings).Error
 	}
 
-	offset := (page - 1) * pageSize
-
-	if err := r.DB.Offset(offset).Limit(pageSize).Find(&bookings).Error; err != nil {
+	if err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 
-	return bookings, nil
+	bookings, info = trimPage(bookings, info, func(b models.Booking) models.Cursor { return b.CreatedCursor() })
+
+	return bookings, info, nil
 
 }
 
@@ -102,7 +136,7 @@ func (r *gormBookingRepository) GetByID(id uint) (*models.Booking, error) {
 	return &booking, nil
 }
 
-func (r *gormBookingRepository) GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error) {
+func (r *gormBookingRepository) GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.PendingBookingRow, error) {
 
 	op := "repository.booking.get_all_pending_by_trip_id"
 
@@ -111,9 +145,21 @@ func (r *gormBookingRepository) GetAllPendingBookingsByTripID(driverID, tripID u
 		slog.Uint64("trip_id", uint64(tripID)),
 	)
 
-	var bookings []models.Booking
+	var bookings []models.PendingBookingRow
 
-	if err := r.DB.Where("driver_id = ? AND trip_id = ? AND booking_status = ?", driverID, tripID, "pending").Find(&bookings).Error; err != nil {
+	// у заявки нет driver_id, водитель берётся из поездки
+	if err := r.DB.Model(&models.Booking{}).
+		Select(`bookings.*,
+			passengers.name AS passenger_name,
+			COALESCE(passenger_ratings.avg_rating, 0) AS passenger_rating,
+			COALESCE(passenger_ratings.reviews_count, 0) AS passenger_reviews`).
+		Joins("JOIN trips ON trips.id = bookings.trip_id").
+		Joins("LEFT JOIN users AS passengers ON passengers.id = bookings.passenger_id").
+		Joins("LEFT JOIN passenger_ratings ON passenger_ratings.passenger_id = bookings.passenger_id").
+		Where("trips.driver_id = ? AND bookings.trip_id = ? AND bookings.booking_status = ?",
+			driverID, tripID, constants.BookingPending).
+		Order("bookings.created_at ASC").
+		Scan(&bookings).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
@@ -183,3 +229,321 @@ func (r *gormBookingRepository) WithDB(db *gorm.DB) BookingRepository {
 		logger: r.logger,
 	}
 }
+
+func (r *gormBookingRepository) CountsByTrips(tripIDs []uint) (map[uint]models.BookingCounts, error) {
+	op := "repository.booking.counts_by_trips"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Int("trips", len(tripIDs)),
+	)
+
+	result := make(map[uint]models.BookingCounts, len(tripIDs))
+	if len(tripIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []models.BookingCounts
+
+	if err := r.DB.Model(&models.Booking{}).
+		Select(`trip_id,
+			COUNT(*) FILTER (WHERE booking_status = ?) AS pending,
+			COUNT(*) FILTER (WHERE booking_status = ?) AS approved,
+			COALESCE(SUM(seats) FILTER (WHERE booking_status = ?), 0) AS approved_seats`,
+			constants.BookingPending, constants.BookingApproved, constants.BookingApproved).
+		Where("trip_id IN ?", tripIDs).
+		Group("trip_id").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.TripID] = row
+	}
+
+	return result, nil
+}
+
+func (r *gormBookingRepository) ListManifest(tripID uint) ([]models.ManifestRow, error) {
+	op := "repository.booking.list_manifest"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	var rows []models.ManifestRow
+
+	if err := r.DB.Model(&models.Booking{}).
+		Select("bookings.id AS booking_id, users.id AS passenger_id, users.name, users.phone, bookings.seats").
+		Joins("JOIN users ON users.id = bookings.passenger_id").
+		Where("bookings.trip_id = ? AND bookings.booking_status = ?", tripID, constants.BookingApproved).
+		Order("bookings.created_at").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *gormBookingRepository) ListByPassenger(filter dto.PassengerBookingFilter) ([]models.PassengerBookingRow, models.PageInfo, error) {
+	op := "repository.booking.list_by_passenger"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("passenger_id", uint64(filter.PassengerID)),
+	)
+
+	query := r.DB.Model(&models.Booking{}).
+		Select(`bookings.*,
+			trips.from_city, trips.to_city, trips.start_time, trips.duration_min,
+			trips.price, trips.trip_status, trips.driver_id,
+			drivers.name AS driver_name,
+			cars.brand AS car_brand, cars.car_model AS car_model,
+			cars.color AS car_color, cars.license_plate AS car_license_plate,
+			trips.finished_at,
+			EXISTS (
+				SELECT 1 FROM reviews
+				WHERE reviews.trip_id = bookings.trip_id
+					AND reviews.author_id = bookings.passenger_id
+					AND reviews.deleted_at IS NULL
+			) AS has_review,
+			EXISTS (
+				SELECT 1 FROM bookings AS checked
+				WHERE checked.trip_id = bookings.trip_id
+					AND checked.checked_in_at IS NOT NULL
+					AND checked.deleted_at IS NULL
+			) AS trip_has_check_ins`).
+		Joins("JOIN trips ON trips.id = bookings.trip_id").
+		Joins("LEFT JOIN users AS drivers ON drivers.id = trips.driver_id").
+		Joins("LEFT JOIN cars ON cars.id = trips.car_id").
+		Where("bookings.passenger_id = ?", filter.PassengerID)
+
+	if filter.Status != nil {
+		query = query.Where("bookings.booking_status = ?", *filter.Status)
+	}
+
+	order := pageOrder{keyColumn: "trips.start_time", idColumn: "bookings.id", desc: true}
+
+	switch filter.When {
+	case dto.BookingWhenUpcoming:
+		query = query.Where("trips.trip_status IN ?", []string{
+			string(constants.TripPublished), string(constants.TripInProgress),
+		})
+		order.desc = false
+	case dto.BookingWhenPast:
+		query = query.Where("trips.trip_status = ?", constants.TripCompleted)
+	}
+
+	query, info, err := applyPage(query, models.Page{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		After:    filter.After,
+	}, order, defaultPageSize)
+
+	var rows []models.PassengerBookingRow
+
+	if err == nil {
+		err = query.Scan(&rows).Error
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	rows, info = trimPage(rows, info, func(row models.PassengerBookingRow) models.Cursor {
+		return models.Cursor{Key: row.StartTime, ID: row.ID}
+	})
+
+	return rows, info, nil
+}
+
+func (r *gormBookingRepository) RejectPendingByTrip(tripID uint) ([]models.Booking, error) {
+	op := "repository.booking.reject_pending_by_trip"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	var rejected []models.Booking
+
+	err := r.DB.Model(&rejected).
+		Clauses(clause.Returning{}).
+		Where("trip_id = ? AND booking_status = ?", tripID, constants.BookingPending).
+		Update("booking_status", constants.BookingRejected).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return rejected, nil
+}
+
+func (r *gormBookingRepository) ListActiveBetween(a, b uint, now time.Time) ([]models.Booking, error) {
+	op := "repository.booking.list_active_between"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("a", uint64(a)),
+		slog.Uint64("b", uint64(b)),
+	)
+
+	var bookings []models.Booking
+
+	err := r.DB.Model(&models.Booking{}).
+		Joins("JOIN trips ON trips.id = bookings.trip_id AND trips.deleted_at IS NULL").
+		Where("bookings.booking_status IN ?", []constants.BookingStatus{constants.BookingPending, constants.BookingApproved}).
+		Where("trips.trip_status = ? AND trips.start_time > ?", constants.TripPublished, now).
+		Where("(bookings.passenger_id = ? AND trips.driver_id = ?) OR (bookings.passenger_id = ? AND trips.driver_id = ?)", a, b, b, a).
+		Order("bookings.id").
+		Find(&bookings).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (r *gormBookingRepository) ListActiveByPassenger(passengerID uint, now time.Time) ([]models.Booking, error) {
+	op := "repository.booking.list_active_by_passenger"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("passenger_id", uint64(passengerID)),
+	)
+
+	var bookings []models.Booking
+
+	err := r.DB.Model(&models.Booking{}).
+		Joins("JOIN trips ON trips.id = bookings.trip_id AND trips.deleted_at IS NULL").
+		Where("bookings.booking_status IN ?", []constants.BookingStatus{constants.BookingPending, constants.BookingApproved}).
+		Where("trips.trip_status = ? AND trips.start_time > ?", constants.TripPublished, now).
+		Where("bookings.passenger_id = ?", passengerID).
+		Order("bookings.id").
+		Find(&bookings).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (r *gormBookingRepository) CancelActiveByTrip(tripID uint) ([]models.Booking, error) {
+	op := "repository.booking.cancel_active_by_trip"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	var cancelled []models.Booking
+
+	err := r.DB.Model(&cancelled).
+		Clauses(clause.Returning{}).
+		Where("trip_id = ? AND booking_status IN ?", tripID, []constants.BookingStatus{constants.BookingPending, constants.BookingApproved}).
+		Update("booking_status", constants.BookingCancelled).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return cancelled, nil
+}
+
+func (r *gormBookingRepository) CheckIn(bookingID uint, now time.Time) (bool, error) {
+	op := "repository.booking.check_in"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("booking_id", uint64(bookingID)),
+	)
+
+	result := r.DB.Model(&models.Booking{}).
+		Where("id = ? AND booking_status = ? AND checked_in_at IS NULL", bookingID, constants.BookingApproved).
+		Update("checked_in_at", now)
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormBookingRepository) MarkNoShow(bookingID uint) (bool, error) {
+	op := "repository.booking.mark_no_show"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("booking_id", uint64(bookingID)),
+	)
+
+	result := r.DB.Model(&models.Booking{}).
+		Where("id = ? AND booking_status = ? AND checked_in_at IS NULL", bookingID, constants.BookingApproved).
+		Update("booking_status", constants.BookingNoShow)
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormBookingRepository) GetForReview(tripID, passengerID uint) (*models.Booking, error) {
+	op := "repository.booking.get_for_review"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+		slog.Uint64("passenger_id", uint64(passengerID)),
+	)
+
+	var booking models.Booking
+
+	if err := r.DB.
+		Where("trip_id = ? AND passenger_id = ?", tripID, passengerID).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "booking_status = ? DESC, created_at DESC",
+			Vars: []any{constants.BookingApproved},
+		}}).
+		First(&booking).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+func (r *gormBookingRepository) HasCheckIns(tripID uint) (bool, error) {
+	op := "repository.booking.has_check_ins"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	var count int64
+
+	if err := r.DB.Model(&models.Booking{}).
+		Where("trip_id = ? AND checked_in_at IS NOT NULL", tripID).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -11,7 +11,7 @@ import (
 type CarRepository interface {
 	Create(car *models.Car) error
 
-	List(filter models.Page) ([]models.Car, error)
+	List(filter models.Page) ([]models.Car, models.PageInfo, error)
 
 	GetByOwner(id uint) (*models.Car, error)
 
@@ -20,6 +20,10 @@ type CarRepository interface {
 	Delete(id uint) error
 
 	GetByID(id uint) (*models.Car, error)
+
+	ExistsByLicensePlate(plate string, excludeID uint) (bool, error)
+
+	WithDB(db *gorm.DB) CarRepository
 }
 
 type gormCarRepository struct {
@@ -44,6 +48,11 @@ func (r *gormCarRepository) Create(car *models.Car) error {
 
 	err := r.db.Create(car).Error
 
+	if isUniqueViolation(err) {
+		r.logger.Warn("Номер уже зарегистрирован", slog.String("license_plate", car.LicensePlate))
+		return ErrDuplicate
+	}
+
 	if err != nil {
 		r.logger.Error(
 			"Ошибка при создании автомобиля",
@@ -106,38 +115,37 @@ func (r *gormCarRepository) GetByID(id uint) (*models.Car, error) {
 	return &car, nil
 }
 
-func (r *gormCarRepository) List(filter models.Page) ([]models.Car, error) {
+func (r *gormCarRepository) List(filter models.Page) ([]models.Car, models.PageInfo, error) {
 	r.logger.Info("Запрос списка автомобилей")
 
 	var cars []models.Car
 
-	page := filter.Page
-	pageSize := filter.PageSize
+	query, info, err := applyPage(r.db.Model(&models.Car{}), filter, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+		desc:      true,
+	}, defaultPageSize)
 
-	if page < 1 {
-		page = 1
+	if err == nil {
+		err = query.Find(&cars).Error
 	}
 
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 100
-	}
-
-	offset := (page - 1) * pageSize
-
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&cars).Error; err != nil {
+	if err != nil {
 		r.logger.Error(
 			"Ошибка при получении списка автомобилей",
 			slog.String("error", err.Error()),
 		)
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 
+	cars, info = trimPage(cars, info, func(c models.Car) models.Cursor { return c.CreatedCursor() })
+
 	r.logger.Info(
 		"Список автомобилей успешно получен",
 		slog.Int("count", len(cars)),
 	)
 
-	return cars, nil
+	return cars, info, nil
 }
 
 func (r *gormCarRepository) Update(car *models.Car) (*models.Car, error) {
@@ -146,7 +154,14 @@ func (r *gormCarRepository) Update(car *models.Car) (*models.Car, error) {
 		slog.Uint64("car_id", uint64(car.ID)),
 	)
 
-	if err := r.db.Save(car).Error; err != nil {
+	err := r.db.Save(car).Error
+
+	if isUniqueViolation(err) {
+		r.logger.Warn("Номер уже зарегистрирован", slog.String("license_plate", car.LicensePlate))
+		return nil, ErrDuplicate
+	}
+
+	if err != nil {
 		r.logger.Error(
 			"Ошибка при обновлении автомобиля",
 			slog.Uint64("car_id", uint64(car.ID)),
@@ -185,3 +200,30 @@ func (r *gormCarRepository) Delete(id uint) error {
 
 	return nil
 }
+
+func (r *gormCarRepository) ExistsByLicensePlate(plate string, excludeID uint) (bool, error) {
+	var count int64
+
+	query := r.db.Model(&models.Car{}).Where("license_plate = ?", plate)
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Error(
+			"Ошибка при проверке номера автомобиля",
+			slog.String("license_plate", plate),
+			slog.String("error", err.Error()),
+		)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *gormCarRepository) WithDB(db *gorm.DB) CarRepository {
+	return &gormCarRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DataExportRepository interface {
+	Create(export *models.DataExport) error
+
+	GetByID(id uint) (*models.DataExport, error)
+
+	ListByUser(userID uint) ([]models.DataExport, error)
+
+	// HasActive — есть ли у пользователя выгрузка в очереди или в работе
+	HasActive(userID uint) (bool, error)
+
+	// ClaimPending забирает ожидающие выгрузки в работу; параллельные воркеры не получат одну и ту же
+	ClaimPending(limit int) ([]models.DataExport, error)
+
+	// ResetStale возвращает в очередь выгрузки, зависшие в работе дольше before (например, после падения)
+	ResetStale(before time.Time) (int64, error)
+
+	MarkReady(id uint, key string, size int64, at, expiresAt time.Time) error
+
+	MarkFailed(id uint, reason string) error
+
+	// ListExpired — готовые выгрузки, срок хранения которых истёк
+	ListExpired(now time.Time) ([]models.DataExport, error)
+
+	MarkExpired(id uint) error
+
+	// Collect собирает все данные пользователя для архива
+	Collect(userID uint) (*dto.PersonalData, error)
+}
+
+type gormDataExportRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewDataExportRepository(db *gorm.DB, logger *slog.Logger) DataExportRepository {
+	return &gormDataExportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormDataExportRepository) Create(export *models.DataExport) error {
+	op := "repository.data_export.create"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(export.UserID)))
+
+	if err := r.db.Create(export).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormDataExportRepository) GetByID(id uint) (*models.DataExport, error) {
+	op := "repository.data_export.get_by_id"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("export_id", uint64(id)))
+
+	var export models.DataExport
+	if err := r.db.First(&export, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *gormDataExportRepository) ListByUser(userID uint) ([]models.DataExport, error) {
+	op := "repository.data_export.list_by_user"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var exports []models.DataExport
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *gormDataExportRepository) HasActive(userID uint) (bool, error) {
+	op := "repository.data_export.has_active"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var count int64
+	err := r.db.Model(&models.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID, []constants.ExportStatus{constants.ExportPending, constants.ExportProcessing}).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormDataExportRepository) ClaimPending(limit int) ([]models.DataExport, error) {
+	op := "repository.data_export.claim_pending"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var exports []models.DataExport
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", constants.ExportPending).
+			Order("id").
+			Limit(limit).
+			Find(&exports).Error
+		if err != nil || len(exports) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(exports))
+		for i := range exports {
+			ids = append(ids, exports[i].ID)
+			exports[i].Status = constants.ExportProcessing
+		}
+
+		return tx.Model(&models.DataExport{}).Where("id IN ?", ids).
+			Update("status", constants.ExportProcessing).Error
+	})
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *gormDataExportRepository) ResetStale(before time.Time) (int64, error) {
+	op := "repository.data_export.reset_stale"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	result := r.db.Model(&models.DataExport{}).
+		Where("status = ? AND updated_at < ?", constants.ExportProcessing, before).
+		Update("status", constants.ExportPending)
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *gormDataExportRepository) MarkReady(id uint, key string, size int64, at, expiresAt time.Time) error {
+	op := "repository.data_export.mark_ready"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("export_id", uint64(id)))
+
+	err := r.db.Model(&models.DataExport{}).Where("id = ?", id).Updates(map[string]any{
+		"status":     constants.ExportReady,
+		"key":        key,
+		"size":       size,
+		"ready_at":   at,
+		"expires_at": expiresAt,
+	}).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormDataExportRepository) MarkFailed(id uint, reason string) error {
+	op := "repository.data_export.mark_failed"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("export_id", uint64(id)))
+
+	err := r.db.Model(&models.DataExport{}).Where("id = ?", id).Updates(map[string]any{
+		"status": constants.ExportFailed,
+		"error":  reason,
+	}).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormDataExportRepository) ListExpired(now time.Time) ([]models.DataExport, error) {
+	op := "repository.data_export.list_expired"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var exports []models.DataExport
+	err := r.db.Where("status = ? AND expires_at <= ?", constants.ExportReady, now).Find(&exports).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *gormDataExportRepository) MarkExpired(id uint) error {
+	op := "repository.data_export.mark_expired"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("export_id", uint64(id)))
+
+	err := r.db.Model(&models.DataExport{}).Where("id = ?", id).Updates(map[string]any{
+		"status": constants.ExportExpired,
+		"key":    "",
+	}).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormDataExportRepository) Collect(userID uint) (*dto.PersonalData, error) {
+	op := "repository.data_export.collect"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	data := &dto.PersonalData{}
+
+	if err := r.db.First(&data.User, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	db := r.db.Session(&gorm.Session{})
+
+	sections := []struct {
+		name  string
+		query *gorm.DB
+		dst   any
+	}{
+		{"cars", db.Where("owner_id = ?", userID), &data.Cars},
+		{"car_photos", db.Where("car_id IN (?)", db.Model(&models.Car{}).Select("id").Where("owner_id = ?", userID)), &data.CarPhotos},
+		{"trips", db.Where("driver_id = ?", userID), &data.TripsAsDriver},
+		{"bookings", db.Where("passenger_id = ?", userID), &data.Bookings},
+		{"reviews_written", db.Where("author_id = ?", userID), &data.ReviewsWritten},
+		{"reviews_received", db.Where("target_id = ?", userID), &data.ReviewsReceived},
+		{"review_replies", db.Where("author_id = ?", userID), &data.ReviewReplies},
+		{"review_reports", db.Where("reporter_id = ?", userID), &data.ReviewReports},
+		{"helpful_votes", db.Where("voter_id = ?", userID), &data.HelpfulVotes},
+		{"saved_searches", db.Where("user_id = ?", userID), &data.SavedSearches},
+		{"search_alerts", db.Where("user_id = ?", userID), &data.SearchAlerts},
+		{"blocks", db.Where("blocker_id = ?", userID), &data.Blocks},
+		{"verification_documents", db.Where("user_id = ?", userID), &data.VerificationDocuments},
+		{"sessions", db.Where("user_id = ?", userID), &data.Sessions},
+		{"notifications", db.Where("user_id = ?", userID), &data.Notifications},
+	}
+
+	for _, section := range sections {
+		if err := section.query.Order("created_at").Find(section.dst).Error; err != nil {
+			r.logger.Error("db error", slog.String("op", op), slog.String("section", section.name), slog.Any("error", err))
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DriverRatingRepository interface {
+	// AddReview и RemoveReview сдвигают агрегат водителя на одну оценку
+	// вместе с заполненными оценками по категориям; at — время отзыва, от него зависит
+	// вес оценки в ранжирующей Score
+	AddReview(driverID uint, rating int, sub models.SubRatings, at time.Time) error
+
+	RemoveReview(driverID uint, rating int, sub models.SubRatings, at time.Time) error
+
+	// GetByDriver возвращает агрегат; у водителя без отзывов он нулевой
+	GetByDriver(driverID uint) (*models.DriverRating, error)
+
+	// Backfill заполняет агрегаты водителей, у которых их ещё нет, по существующим отзывам
+	Backfill() error
+
+	WithDB(db *gorm.DB) DriverRatingRepository
+}
+
+type gormDriverRatingRepository struct {
+	db      *gorm.DB
+	scoring models.RatingScoring
+	logger  *slog.Logger
+}
+
+func NewDriverRatingRepository(db *gorm.DB, scoring models.RatingScoring, logger *slog.Logger) DriverRatingRepository {
+	return &gormDriverRatingRepository{
+		db:      db,
+		scoring: scoring,
+		logger:  logger,
+	}
+}
+
+// avgAfterExpr — среднее после изменения; в ON CONFLICT / UPDATE справа видны старые значения
+const avgAfterExpr = "COALESCE((driver_ratings.rating_sum + ?)::float / NULLIF(driver_ratings.reviews_count + ?, 0), 0)"
+
+// driverScoreExpr — байесовская оценка водителя поездки на момент at; водители без отзывов
+// получают 0 и идут последними. Оценка — функция сохранённых сумм и at: приведение сумм
+// к новому DecayedAt её не меняет, поэтому курсор, выданный на момент at, не «плывёт»
+func driverScoreExpr(s models.RatingScoring, at time.Time) string {
+	// at — время сервера или из подписанного курсора, в SQL подставляется как литерал
+	factor := fmt.Sprintf(
+		"POWER(0.5::float8, EXTRACT(EPOCH FROM ('%s'::timestamptz - driver_ratings.decayed_at))::float8 / %g)",
+		at.UTC().Format(time.RFC3339Nano), s.HalfLife.Seconds(),
+	)
+
+	return fmt.Sprintf(
+		"COALESCE((SELECT (%g + driver_ratings.decayed_sum * %s) / (%g + driver_ratings.decayed_count * %s) "+
+			"FROM driver_ratings WHERE driver_ratings.driver_id = trips.driver_id AND driver_ratings.reviews_count > 0), 0)",
+		s.PriorWeight*s.PriorMean, factor, s.PriorWeight, factor,
+	)
+}
+
+// decayFactorExpr — во сколько раз затухли суммы с driver_ratings.decayed_at до момента-параметра
+const decayFactorExpr = "POWER(0.5::float8, GREATEST(EXTRACT(EPOCH FROM (?::timestamptz - driver_ratings.decayed_at)), 0)::float8 / ?)"
+
+// decayedAssignments приводит затухающие суммы к now и сдвигает их на deltaSum/deltaCount —
+// без обращения к самим отзывам
+func (r *gormDriverRatingRepository) decayedAssignments(now time.Time, deltaSum, deltaCount float64) map[string]any {
+	halfLife := r.scoring.HalfLife.Seconds()
+
+	sum := gorm.Expr("GREATEST(driver_ratings.decayed_sum * "+decayFactorExpr+" + ?, 0)", now, halfLife, deltaSum)
+	count := gorm.Expr("GREATEST(driver_ratings.decayed_count * "+decayFactorExpr+" + ?, 0)", now, halfLife, deltaCount)
+
+	return map[string]any{
+		"decayed_sum":   sum,
+		"decayed_count": count,
+		"decayed_at":    now,
+	}
+}
+
+func starsColumn(rating int) (string, error) {
+	if rating < 1 || rating > 5 {
+		return "", fmt.Errorf("rating out of range: %d", rating)
+	}
+	return fmt.Sprintf("stars_%d", rating), nil
+}
+
+// categoryColumns — колонки суммы и числа оценок категории в driver_ratings
+func categoryColumns(category constants.ReviewCategory) (string, string) {
+	return string(category) + "_sum", string(category) + "_count"
+}
+
+func (r *gormDriverRatingRepository) AddReview(driverID uint, rating int, sub models.SubRatings, at time.Time) error {
+	op := "repository.driver_rating.add_review"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+		slog.Int("rating", rating),
+	)
+
+	stars, err := starsColumn(rating)
+	if err != nil {
+		r.logger.Error("invalid rating", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	now := time.Now()
+
+	updates := map[string]any{
+		"reviews_count": gorm.Expr("driver_ratings.reviews_count + 1"),
+		"rating_sum":    gorm.Expr("driver_ratings.rating_sum + ?", rating),
+		stars:           gorm.Expr(fmt.Sprintf("driver_ratings.%s + 1", stars)),
+		"avg_rating":    gorm.Expr(avgAfterExpr, rating, 1),
+		"updated_at":    now,
+	}
+	w := r.scoring.Weight(at, now)
+
+	values := map[string]any{
+		"driver_id":     driverID,
+		"reviews_count": 1,
+		"rating_sum":    rating,
+		stars:           1,
+		"avg_rating":    float64(rating),
+		"decayed_sum":   w * float64(rating),
+		"decayed_count": w,
+		"decayed_at":    now,
+		"updated_at":    now,
+	}
+
+	for column, expr := range r.decayedAssignments(now, w*float64(rating), w) {
+		updates[column] = expr
+	}
+
+	for category, v := range sub.Values() {
+		sum, count := categoryColumns(category)
+		updates[sum] = gorm.Expr(fmt.Sprintf("driver_ratings.%s + ?", sum), v)
+		updates[count] = gorm.Expr(fmt.Sprintf("driver_ratings.%s + 1", count))
+		values[sum] = v
+		values[count] = 1
+	}
+
+	err = r.db.Model(&models.DriverRating{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "driver_id"}},
+			DoUpdates: clause.Assignments(updates),
+		}).
+		Create(values).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormDriverRatingRepository) RemoveReview(driverID uint, rating int, sub models.SubRatings, at time.Time) error {
+	op := "repository.driver_rating.remove_review"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+		slog.Int("rating", rating),
+	)
+
+	stars, err := starsColumn(rating)
+	if err != nil {
+		r.logger.Error("invalid rating", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	now := time.Now()
+
+	updates := map[string]any{
+		"reviews_count": gorm.Expr("driver_ratings.reviews_count - 1"),
+		"rating_sum":    gorm.Expr("driver_ratings.rating_sum - ?", rating),
+		stars:           gorm.Expr(fmt.Sprintf("GREATEST(driver_ratings.%s - 1, 0)", stars)),
+		"avg_rating":    gorm.Expr(avgAfterExpr, -rating, -1),
+		"updated_at":    now,
+	}
+
+	w := r.scoring.Weight(at, now)
+	for column, expr := range r.decayedAssignments(now, -w*float64(rating), -w) {
+		updates[column] = expr
+	}
+
+	for category, v := range sub.Values() {
+		sum, count := categoryColumns(category)
+		updates[sum] = gorm.Expr(fmt.Sprintf("GREATEST(driver_ratings.%s - ?, 0)", sum), v)
+		updates[count] = gorm.Expr(fmt.Sprintf("GREATEST(driver_ratings.%s - 1, 0)", count))
+	}
+
+	err = r.db.Model(&models.DriverRating{}).
+		Where("driver_id = ? AND reviews_count > 0", driverID).
+		Updates(updates).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormDriverRatingRepository) GetByDriver(driverID uint) (*models.DriverRating, error) {
+	op := "repository.driver_rating.get_by_driver"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+	)
+
+	var rating models.DriverRating
+
+	if err := r.db.Where("driver_id = ?", driverID).First(&rating).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.DriverRating{DriverID: driverID}, nil
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &rating, nil
+}
+
+func (r *gormDriverRatingRepository) Backfill() error {
+	op := "repository.driver_rating.backfill"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	err := r.db.Exec(`
+		INSERT INTO driver_ratings
+			(driver_id, reviews_count, rating_sum, avg_rating,
+			 stars_1, stars_2, stars_3, stars_4, stars_5,
+			 punctuality_sum, punctuality_count, driving_safety_sum, driving_safety_count,
+			 car_comfort_sum, car_comfort_count, communication_sum, communication_count,
+			 updated_at)
+		SELECT trips.driver_id, COUNT(*), SUM(reviews.rating), AVG(reviews.rating),
+			COUNT(*) FILTER (WHERE reviews.rating = 1),
+			COUNT(*) FILTER (WHERE reviews.rating = 2),
+			COUNT(*) FILTER (WHERE reviews.rating = 3),
+			COUNT(*) FILTER (WHERE reviews.rating = 4),
+			COUNT(*) FILTER (WHERE reviews.rating = 5),
+			COALESCE(SUM(reviews.sub_punctuality), 0), COUNT(reviews.sub_punctuality),
+			COALESCE(SUM(reviews.sub_driving_safety), 0), COUNT(reviews.sub_driving_safety),
+			COALESCE(SUM(reviews.sub_car_comfort), 0), COUNT(reviews.sub_car_comfort),
+			COALESCE(SUM(reviews.sub_communication), 0), COUNT(reviews.sub_communication),
+			NOW()
+		FROM reviews
+		JOIN trips ON trips.id = reviews.trip_id
+		WHERE reviews.deleted_at IS NULL
+			AND reviews.direction = 'passenger_to_driver'
+			AND reviews.sealed = false
+			AND reviews.status = 'published'
+		GROUP BY trips.driver_id
+		ON CONFLICT (driver_id) DO NOTHING`).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	// затухающие суммы для агрегатов, заведённых до появления ранжирования
+	halfLife := r.scoring.HalfLife.Seconds()
+
+	err = r.db.Exec(`
+		UPDATE driver_ratings
+		SET decayed_sum = agg.decayed_sum,
+			decayed_count = agg.decayed_count,
+			decayed_at = NOW()
+		FROM (
+			SELECT trips.driver_id,
+				SUM(reviews.rating * POWER(0.5::float8, EXTRACT(EPOCH FROM NOW() - reviews.created_at)::float8 / @half_life)) AS decayed_sum,
+				SUM(POWER(0.5::float8, EXTRACT(EPOCH FROM NOW() - reviews.created_at)::float8 / @half_life)) AS decayed_count
+			FROM reviews
+			JOIN trips ON trips.id = reviews.trip_id
+			WHERE reviews.deleted_at IS NULL
+				AND reviews.direction = 'passenger_to_driver'
+				AND reviews.sealed = false
+				AND reviews.status = 'published'
+			GROUP BY trips.driver_id
+		) AS agg
+		WHERE driver_ratings.driver_id = agg.driver_id
+			AND driver_ratings.reviews_count > 0
+			AND driver_ratings.decayed_count = 0`,
+		sql.Named("half_life", halfLife),
+	).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormDriverRatingRepository) WithDB(db *gorm.DB) DriverRatingRepository {
+	return &gormDriverRatingRepository{
+		db:      db,
+		scoring: r.scoring,
+		logger:  r.logger,
+	}
+}
@@ -5,4 +5,16 @@ import "errors"
 // Общие sentinel-ошибки, возвращаемые слоями репозиториев
 var (
 	ErrNotFound = errors.New("resource not found")
+
+	// ErrDuplicate — запись нарушает уникальный индекс; обычно это гонка двух одинаковых запросов
+	ErrDuplicate = errors.New("duplicate record")
 )
+
+// uniqueViolation — SQLSTATE нарушения уникального индекса в PostgreSQL
+const uniqueViolation = "23505"
+
+// isUniqueViolation распознаёт ошибку драйвера по SQLSTATE, не завязываясь на сам драйвер
+func isUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == uniqueViolation
+}
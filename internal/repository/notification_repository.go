@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository — outbox уведомлений
+type NotificationRepository interface {
+	Create(notifications []models.Notification) error
+
+	// ClaimDue забирает уведомления, которым пора уйти, и откладывает их на lease:
+	// параллельные воркеры их не получат, а если воркер упадёт, запись снова станет доступна
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.Notification, error)
+
+	MarkSent(id uint, attempts int, at time.Time) error
+
+	// MarkRetry записывает ошибку и время следующей попытки
+	MarkRetry(id uint, attempts int, nextAttemptAt time.Time, reason string) error
+
+	// MarkFailed — попытки исчерпаны, уведомление больше не отправляется
+	MarkFailed(id uint, attempts int, reason string) error
+
+	WithDB(db *gorm.DB) NotificationRepository
+}
+
+type gormNotificationRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewNotificationRepository(db *gorm.DB, logger *slog.Logger) NotificationRepository {
+	return &gormNotificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormNotificationRepository) Create(notifications []models.Notification) error {
+	op := "repository.notification.create"
+	r.logger.Debug("db call", slog.String("op", op), slog.Int("count", len(notifications)))
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	if err := r.db.Create(&notifications).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormNotificationRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.Notification, error) {
+	op := "repository.notification.claim_due"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var notifications []models.Notification
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", constants.NotificationPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&notifications).Error
+		if err != nil || len(notifications) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(notifications))
+		for i := range notifications {
+			ids = append(ids, notifications[i].ID)
+		}
+
+		return tx.Model(&models.Notification{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *gormNotificationRepository) MarkSent(id uint, attempts int, at time.Time) error {
+	op := "repository.notification.mark_sent"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("notification_id", uint64(id)))
+
+	return r.update(op, id, map[string]any{
+		"status":     constants.NotificationSent,
+		"attempts":   attempts,
+		"sent_at":    at,
+		"last_error": "",
+	})
+}
+
+func (r *gormNotificationRepository) MarkRetry(id uint, attempts int, nextAttemptAt time.Time, reason string) error {
+	op := "repository.notification.mark_retry"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("notification_id", uint64(id)))
+
+	return r.update(op, id, map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      reason,
+	})
+}
+
+func (r *gormNotificationRepository) MarkFailed(id uint, attempts int, reason string) error {
+	op := "repository.notification.mark_failed"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("notification_id", uint64(id)))
+
+	return r.update(op, id, map[string]any{
+		"status":     constants.NotificationFailed,
+		"attempts":   attempts,
+		"last_error": reason,
+	})
+}
+
+func (r *gormNotificationRepository) update(op string, id uint, columns map[string]any) error {
+	if err := r.db.Model(&models.Notification{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormNotificationRepository) WithDB(db *gorm.DB) NotificationRepository {
+	return &gormNotificationRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
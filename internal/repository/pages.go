@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 100
+)
+
+// pageOrder описывает сортировку списка: ключ + id для однозначного порядка.
+// scoreExpr (необязательный) сортирует раньше ключа, значение попадает в Cursor.Score.
+type pageOrder struct {
+	scoreExpr string
+	keyColumn string
+	idColumn  string
+	desc      bool
+}
+
+// applyPage считает общее число записей по фильтру, затем добавляет к запросу ORDER BY,
+// условие keyset-курсора (или OFFSET для старых клиентов с page/pageSize) и LIMIT
+// на одну запись больше, чтобы понять, есть ли продолжение
+func applyPage(query *gorm.DB, page models.Page, order pageOrder, defaultSize int) (*gorm.DB, models.PageInfo, error) {
+	pageSize := page.PageSize
+	if pageSize <= 0 || pageSize > maxPageSize {
+		pageSize = defaultSize
+	}
+
+	info := models.PageInfo{PageSize: pageSize}
+
+	// Session, чтобы COUNT не испортил исходный запрос
+	if err := query.Session(&gorm.Session{}).Count(&info.Total).Error; err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if order.desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if order.scoreExpr != "" {
+		query = query.Order(fmt.Sprintf("%s %s", order.scoreExpr, dir))
+	}
+	query = query.Order(fmt.Sprintf("%s %s, %s %s", order.keyColumn, dir, order.idColumn, dir))
+
+	if page.After != nil && order.scoreExpr != "" {
+		query = query.Where(
+			fmt.Sprintf("(%s, %s, %s) %s (?, ?, ?)", order.scoreExpr, order.keyColumn, order.idColumn, cmp),
+			page.After.Score, page.After.Key, page.After.ID,
+		)
+	} else if page.After != nil {
+		query = query.Where(
+			fmt.Sprintf("(%s, %s) %s (?, ?)", order.keyColumn, order.idColumn, cmp),
+			page.After.Key, page.After.ID,
+		)
+	} else {
+		info.Page = page.Page
+		if info.Page < 1 {
+			info.Page = 1
+		}
+		query = query.Offset((info.Page - 1) * pageSize)
+	}
+
+	return query.Limit(pageSize + 1), info, nil
+}
+
+// trimPage отрезает лишнюю запись и строит курсор по последнему элементу страницы
+func trimPage[T any](items []T, info models.PageInfo, cursorOf func(T) models.Cursor) ([]T, models.PageInfo) {
+	if len(items) <= info.PageSize {
+		return items, info
+	}
+
+	items = items[:info.PageSize]
+	next := cursorOf(items[len(items)-1])
+	info.Next = &next
+
+	return items, info
+}
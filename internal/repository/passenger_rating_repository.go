@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PassengerRatingRepository interface {
+	AddReview(passengerID uint, rating int) error
+
+	RemoveReview(passengerID uint, rating int) error
+
+	WithDB(db *gorm.DB) PassengerRatingRepository
+}
+
+type gormPassengerRatingRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewPassengerRatingRepository(db *gorm.DB, logger *slog.Logger) PassengerRatingRepository {
+	return &gormPassengerRatingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const passengerAvgAfterExpr = "COALESCE((passenger_ratings.rating_sum + ?)::float / NULLIF(passenger_ratings.reviews_count + ?, 0), 0)"
+
+func (r *gormPassengerRatingRepository) AddReview(passengerID uint, rating int) error {
+	op := "repository.passenger_rating.add_review"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("passenger_id", uint64(passengerID)),
+		slog.Int("rating", rating),
+	)
+
+	now := time.Now()
+
+	err := r.db.Model(&models.PassengerRating{}).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "passenger_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"reviews_count": gorm.Expr("passenger_ratings.reviews_count + 1"),
+				"rating_sum":    gorm.Expr("passenger_ratings.rating_sum + ?", rating),
+				"avg_rating":    gorm.Expr(passengerAvgAfterExpr, rating, 1),
+				"updated_at":    now,
+			}),
+		}).
+		Create(map[string]any{
+			"passenger_id":  passengerID,
+			"reviews_count": 1,
+			"rating_sum":    rating,
+			"avg_rating":    float64(rating),
+			"updated_at":    now,
+		}).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPassengerRatingRepository) RemoveReview(passengerID uint, rating int) error {
+	op := "repository.passenger_rating.remove_review"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("passenger_id", uint64(passengerID)),
+		slog.Int("rating", rating),
+	)
+
+	err := r.db.Model(&models.PassengerRating{}).
+		Where("passenger_id = ? AND reviews_count > 0", passengerID).
+		Updates(map[string]any{
+			"reviews_count": gorm.Expr("passenger_ratings.reviews_count - 1"),
+			"rating_sum":    gorm.Expr("passenger_ratings.rating_sum - ?", rating),
+			"avg_rating":    gorm.Expr(passengerAvgAfterExpr, -rating, -1),
+			"updated_at":    time.Now(),
+		}).Error
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPassengerRatingRepository) WithDB(db *gorm.DB) PassengerRatingRepository {
+	return &gormPassengerRatingRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
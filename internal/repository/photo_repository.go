@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type PhotoRepository interface {
+	CreateCarPhoto(photo *models.CarPhoto) error
+
+	ListCarPhotos(carID uint) ([]models.CarPhoto, error)
+
+	GetCarPhoto(carID, photoID uint) (*models.CarPhoto, error)
+
+	CountCarPhotos(carID uint) (int64, error)
+
+	DeleteCarPhoto(id uint) error
+}
+
+type gormPhotoRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewPhotoRepository(db *gorm.DB, logger *slog.Logger) PhotoRepository {
+	return &gormPhotoRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormPhotoRepository) CreateCarPhoto(photo *models.CarPhoto) error {
+	op := "repository.photo.create_car_photo"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("car_id", uint64(photo.CarID)),
+	)
+
+	if err := r.db.Create(photo).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormPhotoRepository) ListCarPhotos(carID uint) ([]models.CarPhoto, error) {
+	op := "repository.photo.list_car_photos"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("car_id", uint64(carID)),
+	)
+
+	var photos []models.CarPhoto
+
+	if err := r.db.Where("car_id = ?", carID).Order("id").Find(&photos).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return photos, nil
+}
+
+func (r *gormPhotoRepository) GetCarPhoto(carID, photoID uint) (*models.CarPhoto, error) {
+	op := "repository.photo.get_car_photo"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("car_id", uint64(carID)),
+		slog.Uint64("photo_id", uint64(photoID)),
+	)
+
+	var photo models.CarPhoto
+
+	if err := r.db.Where("id = ? AND car_id = ?", photoID, carID).First(&photo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+func (r *gormPhotoRepository) CountCarPhotos(carID uint) (int64, error) {
+	op := "repository.photo.count_car_photos"
+
+	var count int64
+
+	if err := r.db.Model(&models.CarPhoto{}).Where("car_id = ?", carID).Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormPhotoRepository) DeleteCarPhoto(id uint) error {
+	op := "repository.photo.delete_car_photo"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("photo_id", uint64(id)),
+	)
+
+	if err := r.db.Delete(&models.CarPhoto{}, id).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReviewReplyRepository interface {
+	Create(reply *models.ReviewReply) error
+
+	GetByID(id uint) (*models.ReviewReply, error)
+
+	GetByReview(reviewID uint) (*models.ReviewReply, error)
+
+	// UpdateText сохраняет новый текст вместе с результатом автопроверки
+	UpdateText(reply *models.ReviewReply) error
+
+	// SetStatus переводит ответ из from в to; false, если статус уже успели поменять
+	SetStatus(id uint, from, to constants.ReviewStatus, reason string, moderatorID uint) (bool, error)
+
+	ListForModeration(status constants.ReviewStatus, filter models.Page) ([]models.ReviewReply, models.PageInfo, error)
+
+	WithDB(db *gorm.DB) ReviewReplyRepository
+}
+
+type gormReviewReplyRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewReviewReplyRepository(db *gorm.DB, logger *slog.Logger) ReviewReplyRepository {
+	return &gormReviewReplyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormReviewReplyRepository) Create(reply *models.ReviewReply) error {
+	op := "repository.review_reply.create"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("review_id", uint64(reply.ReviewID)))
+
+	err := r.db.Create(reply).Error
+
+	if isUniqueViolation(err) {
+		r.logger.Warn("reply already exists", slog.String("op", op), slog.Uint64("review_id", uint64(reply.ReviewID)))
+		return ErrDuplicate
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormReviewReplyRepository) GetByID(id uint) (*models.ReviewReply, error) {
+	op := "repository.review_reply.get_by_id"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("id", uint64(id)))
+
+	var reply models.ReviewReply
+	if err := r.db.First(&reply, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (r *gormReviewReplyRepository) GetByReview(reviewID uint) (*models.ReviewReply, error) {
+	op := "repository.review_reply.get_by_review"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("review_id", uint64(reviewID)))
+
+	var reply models.ReviewReply
+	if err := r.db.Where("review_id = ?", reviewID).First(&reply).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (r *gormReviewReplyRepository) UpdateText(reply *models.ReviewReply) error {
+	op := "repository.review_reply.update_text"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("id", uint64(reply.ID)))
+
+	// map, а не структура: пустые флаги тоже должны записаться
+	if err := r.db.Model(&models.ReviewReply{}).
+		Where("id = ?", reply.ID).
+		Updates(map[string]any{
+			"text":             reply.Text,
+			"status":           reply.Status,
+			"moderation_flags": reply.ModerationFlags,
+		}).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormReviewReplyRepository) SetStatus(id uint, from, to constants.ReviewStatus, reason string, moderatorID uint) (bool, error) {
+	op := "repository.review_reply.set_status"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+	)
+
+	result := r.db.Model(&models.ReviewReply{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{
+			"status":            to,
+			"moderation_reason": reason,
+			"moderated_by":      moderatorID,
+			"moderated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormReviewReplyRepository) ListForModeration(status constants.ReviewStatus, filter models.Page) ([]models.ReviewReply, models.PageInfo, error) {
+	op := "repository.review_reply.list_for_moderation"
+	r.logger.Debug("db call", slog.String("op", op), slog.String("status", string(status)))
+
+	var replies []models.ReviewReply
+
+	query, info, err := applyPage(r.db.Model(&models.ReviewReply{}).Where("status = ?", status), filter, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+	}, defaultPageSize)
+
+	if err == nil {
+		err = query.Find(&replies).Error
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	replies, info = trimPage(replies, info, func(rp models.ReviewReply) models.Cursor { return rp.CreatedCursor() })
+
+	return replies, info, nil
+}
+
+func (r *gormReviewReplyRepository) WithDB(db *gorm.DB) ReviewReplyRepository {
+	return &gormReviewReplyRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
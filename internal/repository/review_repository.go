@@ -1,16 +1,30 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ReviewRepository interface {
 	Create(review *models.Review) error
 
-	List(filter models.Page) ([]models.Review, error)
+	List(filter dto.ReviewFilter) ([]models.Review, models.PageInfo, error)
+
+	// RatingHistogram — число опубликованных отзывов на каждую оценку в выборке
+	RatingHistogram(filter dto.ReviewFilter) (map[int]int, error)
+
+	// AddHelpfulVote / RemoveHelpfulVote — false, если отметка уже стоит / её не было
+	AddHelpfulVote(reviewID, voterID uint) (bool, error)
+
+	RemoveHelpfulVote(reviewID, voterID uint) (bool, error)
 
 	GetByID(id uint) (*models.Review, error)
 
@@ -18,10 +32,40 @@ type ReviewRepository interface {
 
 	Delete(id uint) error
 
-	ExistsByTripAndUser(tripID, userID uint) (bool, error)
+	ExistsByTripAndUser(tripID, authorID, targetID uint) (bool, error)
+
+	// GetCounterpart ищет встречный отзыв: автор и адресат поменяны местами
+	GetCounterpart(tripID, authorID, targetID uint) (*models.Review, error)
+
+	// LockPair блокирует до конца транзакции отзывы пары пользователей по поездке:
+	// два встречных отзыва создаются по очереди, и второй видит первый
+	LockPair(tripID, userA, userB uint) error
+
+	// Reveal снимает печать; false, если отзыв уже был вскрыт
+	Reveal(id uint) (bool, error)
+
+	ListDueForReveal(now time.Time, limit int) ([]models.Review, error)
+
+	// BackfillTargets проставляет адресата старым отзывам пассажиров — водителя поездки
+	BackfillTargets() error
+
+	ListForModeration(status constants.ReviewStatus, filter models.Page) ([]models.Review, models.PageInfo, error)
+
+	// SetStatus переводит отзыв из from в to; false, если статус уже успели поменять
+	SetStatus(id uint, from, to constants.ReviewStatus, reason string, moderatorID *uint) (bool, error)
+
+	// CreateReport сохраняет жалобу; false, если этот пользователь уже жаловался на отзыв
+	CreateReport(report *models.ReviewReport) (bool, error)
+
+	CountReports(reviewID uint) (int64, error)
+
+	ListReports(reviewID uint) ([]models.ReviewReport, error)
 
 	GetAvgRatingByTrip(tripID uint) (float64, error)
 
+	// ListByDriver возвращает последние отзывы о поездках водителя
+	ListByDriver(driverID uint, limit int) ([]models.DriverReviewRow, error)
+
 	WithDB(db *gorm.DB) ReviewRepository
 }
 
@@ -44,38 +88,178 @@ func (r *gormReviewRepository) Create(review *models.Review) error {
 		slog.Uint64("rating", uint64(review.Rating)),
 		slog.String("text", review.Text),
 	)
-	if err := r.DB.Create(review).Error; err != nil {
+	if err := r.DB.Omit(clause.Associations).Create(review).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return err
 	}
 	return nil
 }
 
-func (r *gormReviewRepository) List(filter models.Page) ([]models.Review, error) {
+// publicReviews — опубликованные отзывы в рамках поездки, водителя или автора
+func (r *gormReviewRepository) publicReviews(filter dto.ReviewFilter) *gorm.DB {
+	query := r.DB.Model(&models.Review{}).Where("sealed = ? AND status = ?", false, constants.ReviewPublished)
+
+	if filter.TripID != nil {
+		query = query.Where("trip_id = ?", *filter.TripID)
+	}
+
+	if filter.DriverID != nil {
+		query = query.Where("direction = ? AND target_id = ?", constants.ReviewPassengerToDriver, *filter.DriverID)
+	}
+
+	if filter.AuthorID != nil {
+		query = query.Where("author_id = ?", *filter.AuthorID)
+	}
+
+	return query
+}
+
+func (r *gormReviewRepository) List(filter dto.ReviewFilter) ([]models.Review, models.PageInfo, error) {
 
 	op := "repository.review.list"
-	r.logger.Debug("db call", slog.String("op", op))
+	r.logger.Debug("db call", slog.String("op", op), slog.String("sort", filter.SortBy))
 	var reviews []models.Review
 
-	page := filter.Page
-	pageSize := filter.PageSize
+	query := r.publicReviews(filter)
 
-	if page < 1 {
-		page = 1
+	if filter.MinRating != nil {
+		query = query.Where("rating >= ?", *filter.MinRating)
 	}
 
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 100
+	if filter.MaxRating != nil {
+		query = query.Where("rating <= ?", *filter.MaxRating)
 	}
 
-	offset := (page - 1) * pageSize
+	order := pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+		desc:      true,
+	}
 
-	if err := r.DB.Offset(offset).Limit(pageSize).Find(&reviews).Error; err != nil {
+	// при равной оценке или полезности — сначала новые
+	score := func(rv models.Review) float64 { return 0 }
+	switch filter.SortBy {
+	case dto.ReviewSortHighest:
+		order.scoreExpr = "rating"
+		score = func(rv models.Review) float64 { return float64(rv.Rating) }
+	case dto.ReviewSortLowest:
+		order.scoreExpr = "-rating"
+		score = func(rv models.Review) float64 { return float64(-rv.Rating) }
+	case dto.ReviewSortHelpful:
+		order.scoreExpr = "helpful_count"
+		score = func(rv models.Review) float64 { return float64(rv.HelpfulCount) }
+	}
+
+	query, info, err := applyPage(query, models.Page{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		After:    filter.After,
+	}, order, defaultPageSize)
+
+	if err == nil {
+		err = publishedReply(query).Find(&reviews).Error
+	}
+
+	if err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
+
+	reviews, info = trimPage(reviews, info, func(rv models.Review) models.Cursor {
+		cursor := rv.CreatedCursor()
+		cursor.Score = score(rv)
+		return cursor
+	})
+
+	// helpful_count меняется между запросами, курсор по нему пропускал бы или повторял
+	// отзывы — такая сортировка листается только номерами страниц
+	if filter.SortBy == dto.ReviewSortHelpful {
+		info.Next = nil
+	}
+
 	r.logger.Debug("db response", slog.String("op", op), slog.Int("count", len(reviews)))
-	return reviews, nil
+	return reviews, info, nil
+}
+
+func (r *gormReviewRepository) RatingHistogram(filter dto.ReviewFilter) (map[int]int, error) {
+
+	op := "repository.review.rating_histogram"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var rows []struct {
+		Rating int
+		Count  int
+	}
+
+	if err := r.publicReviews(filter).
+		Select("rating, COUNT(*) AS count").
+		Group("rating").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	histogram := make(map[int]int, len(rows))
+	for _, row := range rows {
+		histogram[row.Rating] = row.Count
+	}
+	return histogram, nil
+}
+
+func (r *gormReviewRepository) AddHelpfulVote(reviewID, voterID uint) (bool, error) {
+
+	op := "repository.review.add_helpful_vote"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("review_id", uint64(reviewID)),
+		slog.Uint64("voter_id", uint64(voterID)),
+	)
+
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ReviewHelpfulVote{ReviewID: reviewID, VoterID: voterID})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := r.DB.Model(&models.Review{}).
+		Where("id = ?", reviewID).
+		UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *gormReviewRepository) RemoveHelpfulVote(reviewID, voterID uint) (bool, error) {
+
+	op := "repository.review.remove_helpful_vote"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("review_id", uint64(reviewID)),
+		slog.Uint64("voter_id", uint64(voterID)),
+	)
+
+	result := r.DB.Where("review_id = ? AND voter_id = ?", reviewID, voterID).
+		Delete(&models.ReviewHelpfulVote{})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := r.DB.Model(&models.Review{}).
+		Where("id = ? AND helpful_count > 0", reviewID).
+		UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+	return true, nil
 }
 
 func (r *gormReviewRepository) GetByID(id uint) (*models.Review, error) {
@@ -87,13 +271,21 @@ func (r *gormReviewRepository) GetByID(id uint) (*models.Review, error) {
 	)
 	var review models.Review
 
-	if err := r.DB.Where("id = ?", id).First(&review).Error; err != nil {
+	if err := publishedReply(r.DB).Where("id = ?", id).First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
 	return &review, nil
 }
 
+// publishedReply подгружает к отзывам ответ водителя, если он прошёл модерацию
+func publishedReply(db *gorm.DB) *gorm.DB {
+	return db.Preload("Reply", "status = ?", constants.ReviewPublished)
+}
+
 func (r *gormReviewRepository) Update(review *models.Review) (*models.Review, error) {
 
 	op := "repository.review.update"
@@ -101,7 +293,25 @@ func (r *gormReviewRepository) Update(review *models.Review) (*models.Review, er
 		slog.String("op", op),
 		slog.Uint64("id", uint64(review.ID)),
 	)
-	if err := r.DB.Model(&models.Review{}).Where("id = ?", review.ID).Updates(review).Error; err != nil {
+	// только поля, которые меняет автор: map, чтобы записались и нулевые значения
+	// (снятый RatingDerived), а вскрытие и модерация не затирались старой копией
+	values := map[string]any{
+		"text":             review.Text,
+		"rating":           review.Rating,
+		"rating_derived":   review.RatingDerived,
+		"status":           review.Status,
+		"moderation_flags": review.ModerationFlags,
+	}
+	for column, v := range map[string]*int{
+		"sub_punctuality":    review.SubRatings.Punctuality,
+		"sub_driving_safety": review.SubRatings.DrivingSafety,
+		"sub_car_comfort":    review.SubRatings.CarComfort,
+		"sub_communication":  review.SubRatings.Communication,
+	} {
+		values[column] = v
+	}
+
+	if err := r.DB.Model(&models.Review{}).Where("id = ?", review.ID).Updates(values).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
@@ -123,19 +333,20 @@ func (r *gormReviewRepository) Delete(id uint) error {
 	return nil
 }
 
-func (r *gormReviewRepository) ExistsByTripAndUser(tripID, userID uint) (bool, error) {
+func (r *gormReviewRepository) ExistsByTripAndUser(tripID, authorID, targetID uint) (bool, error) {
 
 	op := "repository.review.exists_by_trip_and_user"
 
 	r.logger.Debug("db call",
 		slog.String("op", op),
 		slog.Uint64("trip_id", uint64(tripID)),
-		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("author_id", uint64(authorID)),
+		slog.Uint64("target_id", uint64(targetID)),
 	)
 	var count int64
 
 	if err := r.DB.Model(&models.Review{}).
-		Where("trip_id = ? AND author_id = ?", tripID, userID).
+		Where("trip_id = ? AND author_id = ? AND target_id = ?", tripID, authorID, targetID).
 		Count(&count).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return false, err
@@ -154,8 +365,9 @@ func (r *gormReviewRepository) GetAvgRatingByTrip(tripID uint) (float64, error)
 	var avgRating float64
 
 	if err := r.DB.Model(&models.Review{}).
-		Where("trip_id = ?", tripID).
-		Select("AVG(rating)").
+		Where("trip_id = ? AND direction = ? AND sealed = ? AND status = ?",
+			tripID, constants.ReviewPassengerToDriver, false, constants.ReviewPublished).
+		Select("COALESCE(AVG(rating), 0)").
 		Scan(&avgRating).Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return 0, err
@@ -163,6 +375,228 @@ func (r *gormReviewRepository) GetAvgRatingByTrip(tripID uint) (float64, error)
 	return avgRating, nil
 }
 
+func (r *gormReviewRepository) ListByDriver(driverID uint, limit int) ([]models.DriverReviewRow, error) {
+
+	op := "repository.review.list_by_driver"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+	)
+	var rows []models.DriverReviewRow
+
+	if err := r.DB.Model(&models.Review{}).
+		Select("reviews.*, authors.name AS author_name, "+
+			"review_replies.text AS reply_text, review_replies.created_at AS reply_created_at").
+		Joins("JOIN trips ON trips.id = reviews.trip_id").
+		Joins("LEFT JOIN users AS authors ON authors.id = reviews.author_id").
+		Joins("LEFT JOIN review_replies ON review_replies.review_id = reviews.id "+
+			"AND review_replies.status = ? AND review_replies.deleted_at IS NULL", constants.ReviewPublished).
+		Where("trips.driver_id = ?", driverID).
+		Where("reviews.direction = ? AND reviews.sealed = ? AND reviews.status = ?",
+			constants.ReviewPassengerToDriver, false, constants.ReviewPublished).
+		Order("reviews.created_at DESC, reviews.id DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *gormReviewRepository) GetCounterpart(tripID, authorID, targetID uint) (*models.Review, error) {
+
+	op := "repository.review.get_counterpart"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+	var review models.Review
+
+	if err := r.DB.
+		Where("trip_id = ? AND author_id = ? AND target_id = ?", tripID, targetID, authorID).
+		First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *gormReviewRepository) LockPair(tripID, userA, userB uint) error {
+
+	op := "repository.review.lock_pair"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
+
+	// строки встречного отзыва может ещё не быть, поэтому блокируем не строку, а ключ пары
+	key := fmt.Sprintf("review:%d:%d:%d", tripID, min(userA, userB), max(userA, userB))
+
+	if err := r.DB.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormReviewRepository) Reveal(id uint) (bool, error) {
+
+	op := "repository.review.reveal"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	result := r.DB.Model(&models.Review{}).
+		Where("id = ? AND sealed = ?", id, true).
+		Update("sealed", false)
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormReviewRepository) ListDueForReveal(now time.Time, limit int) ([]models.Review, error) {
+
+	op := "repository.review.list_due_for_reveal"
+	r.logger.Debug("db call", slog.String("op", op))
+	var reviews []models.Review
+
+	if err := r.DB.
+		Where("sealed = ? AND reveal_at <= ?", true, now).
+		Order("reveal_at ASC, id ASC").
+		Limit(limit).
+		Find(&reviews).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *gormReviewRepository) BackfillTargets() error {
+
+	op := "repository.review.backfill_targets"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	if err := r.DB.Exec(`
+		UPDATE reviews SET target_id = trips.driver_id
+		FROM trips
+		WHERE trips.id = reviews.trip_id
+			AND reviews.target_id = 0
+			AND reviews.direction = ?`, constants.ReviewPassengerToDriver).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormReviewRepository) ListForModeration(status constants.ReviewStatus, filter models.Page) ([]models.Review, models.PageInfo, error) {
+
+	op := "repository.review.list_for_moderation"
+	r.logger.Debug("db call", slog.String("op", op), slog.String("status", string(status)))
+	var reviews []models.Review
+
+	// очередь модерации — от старых к новым
+	query, info, err := applyPage(r.DB.Model(&models.Review{}).Where("status = ?", status), filter, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+	}, defaultPageSize)
+
+	if err == nil {
+		err = query.Find(&reviews).Error
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	reviews, info = trimPage(reviews, info, func(rv models.Review) models.Cursor { return rv.CreatedCursor() })
+
+	return reviews, info, nil
+}
+
+func (r *gormReviewRepository) SetStatus(id uint, from, to constants.ReviewStatus, reason string, moderatorID *uint) (bool, error) {
+
+	op := "repository.review.set_status"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+	)
+
+	values := map[string]any{
+		"status":            to,
+		"moderation_reason": reason,
+	}
+	if moderatorID != nil {
+		values["moderated_by"] = *moderatorID
+		values["moderated_at"] = time.Now()
+	}
+
+	result := r.DB.Model(&models.Review{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(values)
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormReviewRepository) CreateReport(report *models.ReviewReport) (bool, error) {
+
+	op := "repository.review.create_report"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("review_id", uint64(report.ReviewID)),
+		slog.Uint64("reporter_id", uint64(report.ReporterID)),
+	)
+
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormReviewRepository) CountReports(reviewID uint) (int64, error) {
+
+	op := "repository.review.count_reports"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("review_id", uint64(reviewID)))
+	var count int64
+
+	if err := r.DB.Model(&models.ReviewReport{}).
+		Where("review_id = ?", reviewID).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *gormReviewRepository) ListReports(reviewID uint) ([]models.ReviewReport, error) {
+
+	op := "repository.review.list_reports"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("review_id", uint64(reviewID)))
+	var reports []models.ReviewReport
+
+	if err := r.DB.
+		Where("review_id = ?", reviewID).
+		Order("created_at ASC").
+		Find(&reports).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return reports, nil
+}
+
 func (r *gormReviewRepository) WithDB(db *gorm.DB) ReviewRepository {
 	return &gormReviewRepository{
 		DB:     db,
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SavedSearchRepository interface {
+	Create(search *models.SavedSearch) error
+
+	ListByUser(userID uint) ([]models.SavedSearch, error)
+
+	GetByID(id uint) (*models.SavedSearch, error)
+
+	Delete(id uint) error
+
+	CountActiveByUser(userID uint) (int64, error)
+
+	// FindMatching возвращает активные поиски, под которые подходит опубликованная поездка
+	FindMatching(trip *models.Trip) ([]models.SavedSearch, error)
+
+	// LockAlerts блокирует до конца транзакции выдачу уведомлений пользователю,
+	// чтобы проверка дневного лимита и запись уведомления шли атомарно
+	LockAlerts(userID uint) error
+
+	CountAlertsSince(userID uint, since time.Time) (int64, error)
+
+	// CreateAlert возвращает false, если пользователь уже получал уведомление об этой поездке
+	CreateAlert(alert *models.SearchAlert) (bool, error)
+
+	WithDB(db *gorm.DB) SavedSearchRepository
+}
+
+type gormSavedSearchRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSavedSearchRepository(db *gorm.DB, logger *slog.Logger) SavedSearchRepository {
+	return &gormSavedSearchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormSavedSearchRepository) Create(search *models.SavedSearch) error {
+	op := "repository.saved_search.create"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(search.UserID)),
+	)
+
+	if err := r.db.Create(search).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormSavedSearchRepository) ListByUser(userID uint) ([]models.SavedSearch, error) {
+	op := "repository.saved_search.list_by_user"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	var searches []models.SavedSearch
+
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&searches).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (r *gormSavedSearchRepository) GetByID(id uint) (*models.SavedSearch, error) {
+	op := "repository.saved_search.get_by_id"
+
+	var search models.SavedSearch
+
+	if err := r.db.First(&search, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &search, nil
+}
+
+func (r *gormSavedSearchRepository) Delete(id uint) error {
+	op := "repository.saved_search.delete"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	if err := r.db.Delete(&models.SavedSearch{}, id).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormSavedSearchRepository) CountActiveByUser(userID uint) (int64, error) {
+	op := "repository.saved_search.count_active_by_user"
+
+	var count int64
+
+	if err := r.db.Model(&models.SavedSearch{}).
+		Where("user_id = ? AND active = ?", userID, true).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormSavedSearchRepository) FindMatching(trip *models.Trip) ([]models.SavedSearch, error) {
+	op := "repository.saved_search.find_matching"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(trip.ID)),
+	)
+
+	var searches []models.SavedSearch
+
+	// поездка подключается, чтобы переиспользовать blockedPairExpr: о поездках водителя,
+	// с которым есть блокировка в любую сторону, не уведомляем
+	subscriber := clause.Column{Table: "saved_searches", Name: "user_id"}
+
+	if err := r.db.
+		Joins("JOIN trips ON trips.id = ?", trip.ID).
+		Where("saved_searches.active = ?", true).
+		Where("saved_searches.user_id <> ?", trip.DriverID).
+		Where("NOT "+blockedPairExpr, subscriber, subscriber).
+		Where("LOWER(saved_searches.from_city) = LOWER(?) AND LOWER(saved_searches.to_city) = LOWER(?)", trip.FromCity, trip.ToCity).
+		Where("saved_searches.date_from IS NULL OR saved_searches.date_from <= ?", trip.StartTime).
+		Where("saved_searches.date_to IS NULL OR saved_searches.date_to >= ?", trip.StartTime).
+		Where("saved_searches.seats <= ?", trip.AvailableSeats).
+		Where("saved_searches.max_price IS NULL OR saved_searches.max_price >= ?", trip.Price).
+		Order("saved_searches.id").
+		Find(&searches).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (r *gormSavedSearchRepository) LockAlerts(userID uint) error {
+	op := "repository.saved_search.lock_alerts"
+
+	// блокируем строку пользователя: параллельные публикации поездок выстраиваются в очередь
+	var id uint
+	if err := r.db.Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userID).
+		Pluck("id", &id).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormSavedSearchRepository) CountAlertsSince(userID uint, since time.Time) (int64, error) {
+	op := "repository.saved_search.count_alerts_since"
+
+	var count int64
+
+	if err := r.db.Model(&models.SearchAlert{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *gormSavedSearchRepository) CreateAlert(alert *models.SearchAlert) (bool, error) {
+	op := "repository.saved_search.create_alert"
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormSavedSearchRepository) WithDB(db *gorm.DB) SavedSearchRepository {
+	return &gormSavedSearchRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+
+	GetByID(id uint) (*models.Session, error)
+
+	// ListActive — незакрытые и неистёкшие сессии пользователя, последние активные сверху
+	ListActive(userID uint, now time.Time) ([]models.Session, error)
+
+	// Touch отмечает активность сессии
+	Touch(id uint, at time.Time, ip string) error
+
+	// Extend продлевает сессию после обновления токена
+	Extend(id uint, at, expiresAt time.Time, ip, userAgent string) error
+
+	// Revoke — false, если сессия уже закрыта
+	Revoke(id uint, reason constants.SessionRevokeReason, at time.Time) (bool, error)
+
+	// RevokeAll закрывает все открытые сессии пользователя, кроме exceptID (0 — без исключений)
+	RevokeAll(userID, exceptID uint, reason constants.SessionRevokeReason, at time.Time) (int64, error)
+
+	CreateRefreshToken(token *models.RefreshToken) error
+
+	GetRefreshToken(hash string) (*models.RefreshToken, error)
+
+	// UseRefreshToken помечает токен использованным; false — его уже использовали
+	UseRefreshToken(id uint, at time.Time) (bool, error)
+
+	WithDB(db *gorm.DB) SessionRepository
+}
+
+type gormSessionRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewSessionRepository(db *gorm.DB, logger *slog.Logger) SessionRepository {
+	return &gormSessionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormSessionRepository) Create(session *models.Session) error {
+	op := "repository.session.create"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(session.UserID)))
+
+	if err := r.db.Create(session).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormSessionRepository) GetByID(id uint) (*models.Session, error) {
+	op := "repository.session.get_by_id"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("session_id", uint64(id)))
+
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *gormSessionRepository) ListActive(userID uint, now time.Time) ([]models.Session, error) {
+	op := "repository.session.list_active"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var sessions []models.Session
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *gormSessionRepository) Touch(id uint, at time.Time, ip string) error {
+	op := "repository.session.touch"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("session_id", uint64(id)))
+
+	err := r.db.Model(&models.Session{}).Where("id = ?", id).
+		Updates(map[string]any{"last_seen_at": at, "ip": ip}).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormSessionRepository) Extend(id uint, at, expiresAt time.Time, ip, userAgent string) error {
+	op := "repository.session.extend"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("session_id", uint64(id)))
+
+	err := r.db.Model(&models.Session{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"last_seen_at": at,
+			"expires_at":   expiresAt,
+			"ip":           ip,
+			"user_agent":   userAgent,
+		}).Error
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormSessionRepository) Revoke(id uint, reason constants.SessionRevokeReason, at time.Time) (bool, error) {
+	op := "repository.session.revoke"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("session_id", uint64(id)))
+
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": at, "revoke_reason": reason})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormSessionRepository) RevokeAll(userID, exceptID uint, reason constants.SessionRevokeReason, at time.Time) (int64, error) {
+	op := "repository.session.revoke_all"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Updates(map[string]any{"revoked_at": at, "revoke_reason": reason})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *gormSessionRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	op := "repository.session.create_refresh_token"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("session_id", uint64(token.SessionID)))
+
+	if err := r.db.Create(token).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormSessionRepository) GetRefreshToken(hash string) (*models.RefreshToken, error) {
+	op := "repository.session.get_refresh_token"
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var token models.RefreshToken
+	if err := r.db.Where("hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *gormSessionRepository) UseRefreshToken(id uint, at time.Time) (bool, error) {
+	op := "repository.session.use_refresh_token"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("token_id", uint64(id)))
+
+	// условие на used_at: из двух одновременных обновлений одним токеном пройдёт только одно
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormSessionRepository) WithDB(db *gorm.DB) SessionRepository {
+	return &gormSessionRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
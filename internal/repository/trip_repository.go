@@ -5,15 +5,17 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TripRepository interface {
 	Create(trip *models.Trip) error
 
-	List(filter dto.TripFilter) ([]models.Trip, error)
+	List(filter dto.TripFilter) ([]models.Trip, models.PageInfo, error)
 
 	GetByID(id uint) (*models.Trip, error)
 
@@ -25,20 +27,42 @@ type TripRepository interface {
 
 	UpdateAvgRating(tripID uint, avg float64) error
 
-	IsPassenger(tripID, userID uint) (bool, error)
+	// UpdateTripStatuses переводит по времени поездки, которые водитель не начал/не завершил
+	// сам в течение grace, и возвращает ID тех, что сменили статус
+	UpdateTripStatuses(now time.Time, grace time.Duration) (started []uint, completed []uint, err error)
 
-	UpdateTripStatuses(now time.Time) error
+	// MarkStarted и MarkFinished меняют статус, только если поездка ещё в исходном статусе
+	MarkStarted(tripID uint, now time.Time) (bool, error)
+
+	MarkFinished(tripID uint, now time.Time) (bool, error)
+
+	// MarkCancelled отменяет ещё не начавшуюся поездку
+	MarkCancelled(tripID uint) (bool, error)
+
+	UpdatePreferences(tripID uint, prefs models.TripPreferences) error
+
+	ListByDriver(driverID uint, status string, limit int) ([]models.Trip, error)
+
+	// ReserveSeats атомарно занимает места; false — свободных мест не хватает
+	ReserveSeats(tripID uint, seats int) (bool, error)
+
+	// ReleaseSeats атомарно возвращает места
+	ReleaseSeats(tripID uint, seats int) error
+
+	CountByDriver(driverID uint, status string) (int64, error)
 }
 
 type gormTripRepository struct {
-	db     *gorm.DB
-	logger *slog.Logger
+	db      *gorm.DB
+	scoring models.RatingScoring
+	logger  *slog.Logger
 }
 
-func NewTripRepository(db *gorm.DB, logger *slog.Logger) TripRepository {
+func NewTripRepository(db *gorm.DB, scoring models.RatingScoring, logger *slog.Logger) TripRepository {
 	return &gormTripRepository{
-		db:     db,
-		logger: logger,
+		db:      db,
+		scoring: scoring,
+		logger:  logger,
 	}
 }
 
@@ -51,10 +75,24 @@ func (r *gormTripRepository) Create(trip *models.Trip) error {
 	return nil
 }
 
-func (r *gormTripRepository) List(filter dto.TripFilter) ([]models.Trip, error) {
+// driverRatingExpr — средняя оценка водителя поездки, 0 если отзывов ещё нет
+const driverRatingExpr = "COALESCE((SELECT driver_ratings.avg_rating FROM driver_ratings WHERE driver_ratings.driver_id = trips.driver_id), 0)"
+
+// driverVerifiedExpr — бейдж проверенного водителя поездки
+const driverVerifiedExpr = "COALESCE((SELECT users.driver_verified FROM users WHERE users.id = trips.driver_id), false)"
+
+func (r *gormTripRepository) List(filter dto.TripFilter) ([]models.Trip, models.PageInfo, error) {
 	var list []models.Trip
 
+	// все страницы одной выдачи ранжируются на момент первой, иначе оценки затухают между запросами
+	asOf := time.Now().UTC()
+	if filter.After != nil && !filter.After.AsOf.IsZero() {
+		asOf = filter.After.AsOf
+	}
+	scoreExpr := driverScoreExpr(r.scoring, asOf)
+
 	query := r.db.Model(&models.Trip{}).
+		Select("trips.*, " + driverRatingExpr + " AS driver_rating, " + scoreExpr + " AS driver_score, " + driverVerifiedExpr + " AS driver_verified").
 		Where("available_seats > 0")
 
 	if filter.FromCity != nil {
@@ -73,33 +111,126 @@ func (r *gormTripRepository) List(filter dto.TripFilter) ([]models.Trip, error)
 		query = query.Where("start_time >= ?", *filter.StartTime)
 	}
 
+	// по умолчанию в поиске только опубликованные и ещё не отправившиеся поездки:
+	// у отменённых могут остаться места, а начатую воркер переводит не сразу
 	if filter.TripStatus != nil {
 		query = query.Where("trip_status = ?", *filter.TripStatus)
+	} else {
+		query = query.Where("trip_status = ? AND start_time > ?", constants.TripPublished, time.Now())
 	}
 
-	page := filter.Page
-	pageSize := filter.PageSize
+	if filter.SmokingAllowed != nil {
+		query = query.Where("pref_smoking_allowed = ?", *filter.SmokingAllowed)
+	}
 
-	if page < 1 {
-		page = 1
+	if filter.PetsAllowed != nil {
+		query = query.Where("pref_pets_allowed = ?", *filter.PetsAllowed)
 	}
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50
+
+	if filter.MusicAllowed != nil {
+		query = query.Where("pref_music_allowed = ?", *filter.MusicAllowed)
 	}
 
-	offset := (page - 1) * pageSize
+	if filter.BigLuggage != nil {
+		query = query.Where("pref_big_luggage = ?", *filter.BigLuggage)
+	}
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&list).Error; err != nil {
-		return nil, err
+	if filter.WomenOnly != nil {
+		query = query.Where("pref_women_only = ?", *filter.WomenOnly)
+	}
+
+	if cars, ok := r.carFilterQuery(filter); ok {
+		query = query.Where("car_id IN (?)", cars)
 	}
 
-	return list, nil
+	if filter.MinDriverRating != nil {
+		query = query.Where(driverRatingExpr+" >= ?", *filter.MinDriverRating)
+	}
+
+	if filter.ViewerID != nil {
+		query = query.Where("NOT "+blockedPairExpr, *filter.ViewerID, *filter.ViewerID)
+	}
+
+	order := pageOrder{
+		keyColumn: "start_time",
+		idColumn:  "id",
+	}
+
+	// лучшие водители сверху: сортируем по ранжирующей оценке со знаком минус,
+	// чтобы весь ключ шёл по возрастанию. Простое среднее не годится: один отзыв
+	// на 5 звёзд обгонял бы две сотни отзывов со средним 4.9.
+	if filter.SortBy == dto.TripSortDriverRating {
+		order.scoreExpr = "-" + scoreExpr
+	}
+
+	query, info, err := applyPage(query, models.Page{
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		After:    filter.After,
+	}, order, 50)
+
+	if err == nil {
+		err = query.Preload("Car").Find(&list).Error
+	}
+
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	list, info = trimPage(list, info, func(t models.Trip) models.Cursor {
+		return models.Cursor{Score: -t.DriverScore, Key: t.StartTime, ID: t.ID, AsOf: asOf}
+	})
+
+	return list, info, nil
+}
+
+// carFilterQuery строит подзапрос по автомобилям, если в фильтре задан хотя бы один параметр машины
+func (r *gormTripRepository) carFilterQuery(filter dto.TripFilter) (*gorm.DB, bool) {
+	query := r.db.Model(&models.Car{}).Select("id")
+	applied := false
+
+	if filter.CarColor != nil {
+		query = query.Where("LOWER(color) = LOWER(?)", *filter.CarColor)
+		applied = true
+	}
+
+	if filter.CarBodyType != nil {
+		query = query.Where("body_type = ?", *filter.CarBodyType)
+		applied = true
+	}
+
+	if filter.MinCarYear != nil {
+		query = query.Where("year >= ?", *filter.MinCarYear)
+		applied = true
+	}
+
+	if filter.AirConditioning != nil {
+		query = query.Where("air_conditioning = ?", *filter.AirConditioning)
+		applied = true
+	}
+
+	if filter.ChildSeat != nil {
+		query = query.Where("child_seat = ?", *filter.ChildSeat)
+		applied = true
+	}
+
+	if filter.WheelchairAccess != nil {
+		query = query.Where("wheelchair_access = ?", *filter.WheelchairAccess)
+		applied = true
+	}
+
+	if filter.MinTrunkCapacity != nil {
+		query = query.Where("trunk_capacity_l >= ?", *filter.MinTrunkCapacity)
+		applied = true
+	}
+
+	return query, applied
 }
 
 func (r *gormTripRepository) GetByID(id uint) (*models.Trip, error) {
 	var trip models.Trip
 
-	if err := r.db.First(&trip, id).Error; err != nil {
+	if err := r.db.Preload("Car").First(&trip, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
 		}
@@ -119,6 +250,7 @@ func (r *gormTripRepository) Update(trip *models.Trip) error {
 
 	return r.db.
 		Model(&models.Trip{}).
+		Omit(clause.Associations).
 		Where("id = ?", trip.ID).
 		Updates(trip).
 		Error
@@ -134,8 +266,9 @@ func (r *gormTripRepository) Delete(id uint) error {
 
 func (r *gormTripRepository) WithDB(db *gorm.DB) TripRepository {
 	return &gormTripRepository{
-		db:     db,
-		logger: r.logger,
+		db:      db,
+		scoring: r.scoring,
+		logger:  r.logger,
 	}
 }
 
@@ -153,36 +286,180 @@ func (r *gormTripRepository) UpdateAvgRating(tripID uint, avg float64) error {
 	return nil
 }
 
-func (r *gormTripRepository) IsPassenger(tripID, userID uint) (bool, error) {
-	var count int64
+func (r *gormTripRepository) UpdateTripStatuses(now time.Time, grace time.Duration) ([]uint, []uint, error) {
+	op := "repository.trip.update_statuses"
 
-	err := r.db.Model(&models.Booking{}).
-		Where("trip_id = ? AND passenger_id = ?", tripID, userID).
-		Count(&count).Error
+	returningID := clause.Returning{Columns: []clause.Column{{Name: "id"}}}
+	graceMin := int(grace.Minutes())
 
-	if err != nil {
-		return false, err
+	var started []models.Trip
+
+	if err := r.db.Model(&started).
+		Clauses(returningID).
+		Where("trip_status = ?", constants.TripPublished).
+		Where("start_time + (? * interval '1 minute') <= ?", graceMin, now).
+		Updates(map[string]any{
+			"trip_status": constants.TripInProgress,
+			"started_at":  now,
+		}).
+		Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, nil, err
 	}
 
-	return count > 0, nil
+	var completed []models.Trip
+
+	if err := r.db.Model(&completed).
+		Clauses(returningID).
+		Where("trip_status = ?", constants.TripInProgress).
+		Where("COALESCE(started_at, start_time) + ((duration_min + ?) * interval '1 minute') <= ?", graceMin, now).
+		Updates(map[string]any{
+			"trip_status": constants.TripCompleted,
+			"finished_at": now,
+		}).
+		Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, nil, err
+	}
+
+	return tripIDs(started), tripIDs(completed), nil
 }
 
-func (r *gormTripRepository) UpdateTripStatuses(now time.Time) error {
-	op := "repository.trip.update_statuses"
+func (r *gormTripRepository) MarkStarted(tripID uint, now time.Time) (bool, error) {
+	return r.transition(tripID, constants.TripPublished, map[string]any{
+		"trip_status": constants.TripInProgress,
+		"started_at":  now,
+	})
+}
+
+func (r *gormTripRepository) MarkFinished(tripID uint, now time.Time) (bool, error) {
+	return r.transition(tripID, constants.TripInProgress, map[string]any{
+		"trip_status": constants.TripCompleted,
+		"finished_at": now,
+	})
+}
+
+func (r *gormTripRepository) MarkCancelled(tripID uint) (bool, error) {
+	return r.transition(tripID, constants.TripPublished, map[string]any{
+		"trip_status": constants.TripCancelled,
+	})
+}
+
+func (r *gormTripRepository) transition(tripID uint, from constants.TripStatus, values map[string]any) (bool, error) {
+	op := "repository.trip.transition"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+		slog.String("from", string(from)),
+	)
+
+	result := r.db.Model(&models.Trip{}).
+		Where("id = ? AND trip_status = ?", tripID, from).
+		Updates(values)
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func tripIDs(trips []models.Trip) []uint {
+	ids := make([]uint, 0, len(trips))
+	for _, t := range trips {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func (r *gormTripRepository) UpdatePreferences(tripID uint, prefs models.TripPreferences) error {
+	op := "repository.trip.update_preferences"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+	)
 
 	if err := r.db.Model(&models.Trip{}).
-		Where("trip_status = ?", "published").
-		Where("start_time <= ?", now).
-		Update("trip_status", "in_progress").
+		Where("id = ?", tripID).
+		Updates(prefs.Columns("pref_")).
 		Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return err
 	}
 
+	return nil
+}
+
+// ListByDriver возвращает поездки водителя с заданным статусом.
+// Завершённые идут от новых к старым, остальные — по времени отправления.
+func (r *gormTripRepository) ListByDriver(driverID uint, status string, limit int) ([]models.Trip, error) {
+	op := "repository.trip.list_by_driver"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+		slog.String("status", status),
+	)
+
+	order := "start_time ASC"
+	if status == string(constants.TripCompleted) {
+		order = "start_time DESC"
+	}
+
+	var trips []models.Trip
+
+	query := r.db.Preload("Car").
+		Where("driver_id = ? AND trip_status = ?", driverID, status).
+		Order(order)
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&trips).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return trips, nil
+}
+
+func (r *gormTripRepository) ReserveSeats(tripID uint, seats int) (bool, error) {
+	op := "repository.trip.reserve_seats"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+		slog.Int("seats", seats),
+	)
+
+	result := r.db.Model(&models.Trip{}).
+		Where("id = ? AND available_seats >= ?", tripID, seats).
+		UpdateColumn("available_seats", gorm.Expr("available_seats - ?", seats))
+
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormTripRepository) ReleaseSeats(tripID uint, seats int) error {
+	op := "repository.trip.release_seats"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(tripID)),
+		slog.Int("seats", seats),
+	)
+
 	if err := r.db.Model(&models.Trip{}).
-		Where("trip_status = ?", "in_progress").
-		Where("start_time + (duration_min * interval '1 minute') <= ?", now).
-		Update("trip_status", "completed").
+		Where("id = ?", tripID).
+		UpdateColumn("available_seats", gorm.Expr("available_seats + ?", seats)).
 		Error; err != nil {
 		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
 		return err
@@ -190,3 +467,25 @@ func (r *gormTripRepository) UpdateTripStatuses(now time.Time) error {
 
 	return nil
 }
+
+func (r *gormTripRepository) CountByDriver(driverID uint, status string) (int64, error) {
+	op := "repository.trip.count_by_driver"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("driver_id", uint64(driverID)),
+		slog.String("status", status),
+	)
+
+	var count int64
+
+	if err := r.db.Model(&models.Trip{}).
+		Where("driver_id = ? AND trip_status = ?", driverID, status).
+		Count(&count).
+		Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	return count, nil
+}
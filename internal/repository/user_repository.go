@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"gorm.io/gorm"
 )
@@ -11,13 +12,37 @@ import (
 type UserRepository interface {
 	Create(user *models.User) error
 
-	List(filter models.Page) ([]models.User, error)
+	List(filter models.Page) ([]models.User, models.PageInfo, error)
 
 	GetByID(id uint) (*models.User, error)
 
 	Update(id uint, user *models.User) error
 
 	Delete(id uint) error
+
+	UpdateDefaultPreferences(id uint, prefs models.TripPreferences) error
+
+	UpdateProfile(id uint, profile models.UserProfile) error
+
+	GetByPhone(phone string) (*models.User, error)
+
+	// PhoneOwner — ID пользователя с этим телефоном, включая удалённых: уникальный
+	// индекс по телефону покрывает и их. ErrNotFound, если номер свободен
+	PhoneOwner(phone string) (uint, error)
+
+	// ListUnnormalizedPhones — пользователи, чей телефон записан не в E.164
+	ListUnnormalizedPhones() ([]models.User, error)
+
+	UpdatePhone(id uint, phone string) error
+
+	SetRole(id uint, role constants.Role) error
+
+	SetPasswordHash(id uint, hash string) error
+
+	// BackfillDriverRoles выдаёт роль водителя пассажирам, у которых уже есть автомобиль
+	BackfillDriverRoles() (int64, error)
+
+	WithDB(db *gorm.DB) UserRepository
 }
 
 type gormUserRepository struct {
@@ -40,7 +65,14 @@ func (r *gormUserRepository) Create(user *models.User) error {
 		slog.String("name", user.Name),
 	)
 
-	if err := r.db.Create(&user).Error; err != nil {
+	err := r.db.Create(&user).Error
+
+	if isUniqueViolation(err) {
+		r.logger.Warn("phone already registered", slog.String("op", op))
+		return ErrDuplicate
+	}
+
+	if err != nil {
 		r.logger.Error("db error",
 			slog.String("op", op),
 			slog.Any("error", err),
@@ -51,7 +83,7 @@ func (r *gormUserRepository) Create(user *models.User) error {
 	return nil
 }
 
-func (r *gormUserRepository) List(filter models.Page) ([]models.User, error) {
+func (r *gormUserRepository) List(filter models.Page) ([]models.User, models.PageInfo, error) {
 	op := "repository.user.list"
 
 	r.logger.Debug("db call",
@@ -60,28 +92,27 @@ func (r *gormUserRepository) List(filter models.Page) ([]models.User, error) {
 
 	var users []models.User
 
-	page := filter.Page
-	pageSize := filter.PageSize
-
-	if page < 1 {
-		page = 1
-	}
+	query, info, err := applyPage(r.db.Model(&models.User{}), filter, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+		desc:      true,
+	}, defaultPageSize)
 
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 100
+	if err == nil {
+		err = query.Find(&users).Error
 	}
 
-	offset := (page - 1) * pageSize
-
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+	if err != nil {
 		r.logger.Error("db error",
 			slog.String("op", op),
 			slog.Any("error", err),
 		)
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 
-	return users, nil
+	users, info = trimPage(users, info, func(u models.User) models.Cursor { return u.CreatedCursor() })
+
+	return users, info, nil
 }
 
 func (r *gormUserRepository) GetByID(id uint) (*models.User, error) {
@@ -117,7 +148,14 @@ func (r gormUserRepository) Update(id uint, user *models.User) error {
 		slog.String("user_name", user.Name),
 	)
 
-	if err := r.db.Model(&models.User{}).Where("id = ?", id).Updates(user).Error; err != nil {
+	err := r.db.Model(&models.User{}).Where("id = ?", id).Updates(user).Error
+
+	if isUniqueViolation(err) {
+		r.logger.Warn("phone already registered", slog.String("op", op))
+		return ErrDuplicate
+	}
+
+	if err != nil {
 		r.logger.Error("db error",
 			slog.String("op", op),
 			slog.Any("error", err),
@@ -146,3 +184,207 @@ func (r *gormUserRepository) Delete(id uint) error {
 
 	return nil
 }
+
+func (r *gormUserRepository) UpdateDefaultPreferences(id uint, prefs models.TripPreferences) error {
+	op := "repository.user.update_default_preferences"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	if err := r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(prefs.Columns("default_pref_")).
+		Error; err != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) UpdateProfile(id uint, profile models.UserProfile) error {
+	op := "repository.user.update_profile"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	if err := r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(profile.Columns()).
+		Error; err != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) GetByPhone(phone string) (*models.User, error) {
+	op := "repository.user.get_by_phone"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var user models.User
+
+	if err := r.db.Where("phone = ?", phone).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *gormUserRepository) ListUnnormalizedPhones() ([]models.User, error) {
+	op := "repository.user.list_unnormalized_phones"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var users []models.User
+
+	if err := r.db.
+		Where("phone !~ ?", `^\+[1-9][0-9]{7,14}$`).
+		Order("id ASC").
+		Find(&users).Error; err != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *gormUserRepository) PhoneOwner(phone string) (uint, error) {
+	op := "repository.user.phone_owner"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	var user models.User
+
+	if err := r.db.Unscoped().Select("id").Where("phone = ?", phone).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrNotFound
+		}
+
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+func (r *gormUserRepository) UpdatePhone(id uint, phone string) error {
+	op := "repository.user.update_phone"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	if err := r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Update("phone", phone).Error; err != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) SetRole(id uint, role constants.Role) error {
+	op := "repository.user.set_role"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+		slog.String("role", string(role)),
+	)
+
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", result.Error),
+		)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) SetPasswordHash(id uint, hash string) error {
+	op := "repository.user.set_password_hash"
+
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("password_hash", hash)
+	if result.Error != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", result.Error),
+		)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *gormUserRepository) BackfillDriverRoles() (int64, error) {
+	op := "repository.user.backfill_driver_roles"
+
+	r.logger.Debug("db call", slog.String("op", op))
+
+	result := r.db.Model(&models.User{}).
+		Where("role = ?", constants.RolePassenger).
+		Where("EXISTS (SELECT 1 FROM cars WHERE cars.owner_id = users.id AND cars.deleted_at IS NULL)").
+		Update("role", constants.RoleDriver)
+	if result.Error != nil {
+		r.logger.Error("db error",
+			slog.String("op", op),
+			slog.Any("error", result.Error),
+		)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *gormUserRepository) WithDB(db *gorm.DB) UserRepository {
+	return &gormUserRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
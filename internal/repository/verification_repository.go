@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"gorm.io/gorm"
+)
+
+type VerificationRepository interface {
+	Create(doc *models.VerificationDocument) error
+
+	GetByID(id uint) (*models.VerificationDocument, error)
+
+	ListByUser(userID uint) ([]models.VerificationDocument, error)
+
+	// HasPending — есть ли у пользователя непроверенный документ этого типа
+	HasPending(userID uint, docType constants.DocumentType) (bool, error)
+
+	// SetStatus переводит документ из from в to; false, если его уже успели проверить
+	SetStatus(id uint, from, to constants.VerificationStatus, reason string, reviewerID uint) (bool, error)
+
+	ListForReview(status constants.VerificationStatus, filter models.Page) ([]models.VerificationDocument, models.PageInfo, error)
+
+	// SetDriverVerified ставит бейдж проверенного водителя
+	SetDriverVerified(userID uint, at time.Time) error
+
+	// SetCarVerified ставит бейдж проверенного автомобиля
+	SetCarVerified(carID uint, at time.Time) error
+
+	WithDB(db *gorm.DB) VerificationRepository
+}
+
+type gormVerificationRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewVerificationRepository(db *gorm.DB, logger *slog.Logger) VerificationRepository {
+	return &gormVerificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *gormVerificationRepository) Create(doc *models.VerificationDocument) error {
+	op := "repository.verification.create"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(doc.UserID)))
+
+	if err := r.db.Create(doc).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+func (r *gormVerificationRepository) GetByID(id uint) (*models.VerificationDocument, error) {
+	op := "repository.verification.get_by_id"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("id", uint64(id)))
+
+	var doc models.VerificationDocument
+	if err := r.db.First(&doc, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (r *gormVerificationRepository) ListByUser(userID uint) ([]models.VerificationDocument, error) {
+	op := "repository.verification.list_by_user"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var docs []models.VerificationDocument
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&docs).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	return docs, nil
+}
+
+func (r *gormVerificationRepository) HasPending(userID uint, docType constants.DocumentType) (bool, error) {
+	op := "repository.verification.has_pending"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	var count int64
+	if err := r.db.Model(&models.VerificationDocument{}).
+		Where("user_id = ? AND type = ? AND status = ?", userID, docType, constants.VerificationPending).
+		Count(&count).Error; err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormVerificationRepository) SetStatus(id uint, from, to constants.VerificationStatus, reason string, reviewerID uint) (bool, error) {
+	op := "repository.verification.set_status"
+	r.logger.Debug("db call",
+		slog.String("op", op),
+		slog.Uint64("id", uint64(id)),
+		slog.String("from", string(from)),
+		slog.String("to", string(to)),
+	)
+
+	result := r.db.Model(&models.VerificationDocument{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{
+			"status":        to,
+			"reject_reason": reason,
+			"reviewed_by":   reviewerID,
+			"reviewed_at":   time.Now(),
+		})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *gormVerificationRepository) ListForReview(status constants.VerificationStatus, filter models.Page) ([]models.VerificationDocument, models.PageInfo, error) {
+	op := "repository.verification.list_for_review"
+	r.logger.Debug("db call", slog.String("op", op), slog.String("status", string(status)))
+
+	var docs []models.VerificationDocument
+
+	query, info, err := applyPage(r.db.Model(&models.VerificationDocument{}).Where("status = ?", status), filter, pageOrder{
+		keyColumn: "created_at",
+		idColumn:  "id",
+	}, defaultPageSize)
+
+	if err == nil {
+		err = query.Find(&docs).Error
+	}
+
+	if err != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	docs, info = trimPage(docs, info, func(d models.VerificationDocument) models.Cursor { return d.CreatedCursor() })
+
+	return docs, info, nil
+}
+
+func (r *gormVerificationRepository) SetDriverVerified(userID uint, at time.Time) error {
+	op := "repository.verification.set_driver_verified"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+
+	result := r.db.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"driver_verified":    true,
+			"driver_verified_at": at,
+		})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormVerificationRepository) SetCarVerified(carID uint, at time.Time) error {
+	op := "repository.verification.set_car_verified"
+	r.logger.Debug("db call", slog.String("op", op), slog.Uint64("car_id", uint64(carID)))
+
+	result := r.db.Model(&models.Car{}).
+		Where("id = ?", carID).
+		Updates(map[string]any{
+			"verified":    true,
+			"verified_at": at,
+		})
+	if result.Error != nil {
+		r.logger.Error("db error", slog.String("op", op), slog.Any("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormVerificationRepository) WithDB(db *gorm.DB) VerificationRepository {
+	return &gormVerificationRepository{
+		db:     db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/notify"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/storage"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExportInProgress   = errors.New("a data export is already in progress")
+	ErrExportNotFound     = errors.New("data export not found")
+	ErrOutstandingBalance = errors.New("account has a positive balance; withdraw it or confirm forfeiting it")
+	ErrTripInProgress     = errors.New("account cannot be erased during a trip in progress")
+)
+
+const (
+	// exportTTL — сколько хранится готовый архив
+	exportTTL = 7 * 24 * time.Hour
+
+	// exportURLTTL — срок действия ссылки на скачивание
+	exportURLTTL = 15 * time.Minute
+
+	// exportStaleAfter — выгрузка, которая так долго «в работе», считается брошенной
+	exportStaleAfter = time.Hour
+
+	exportBatchSize = 10
+)
+
+type AccountService interface {
+	// RequestExport ставит в очередь выгрузку всех данных пользователя
+	RequestExport(userID uint) (*models.DataExport, error)
+
+	ListExports(userID uint) ([]models.DataExport, error)
+
+	// GetExport возвращает выгрузку пользователя и ссылку на архив, если он готов
+	GetExport(ctx context.Context, userID, exportID uint) (*dto.DataExportResponse, error)
+
+	// ProcessExports собирает архивы из очереди и удаляет просроченные; вызывается воркером
+	ProcessExports(ctx context.Context, now time.Time) (int, error)
+
+	// Erase удаляет аккаунт: отменяет будущие поездки и заявки, удаляет личные записи
+	// и файлы, обезличивает имя и телефон. Отзывы остаются под именем FormerUserName.
+	// Положительный баланс списывается, только если forfeitBalance
+	Erase(ctx context.Context, actorID, userID uint, forfeitBalance bool) (*models.AccountErasure, error)
+}
+
+type accountService struct {
+	accountRepo repository.AccountRepository
+	exportRepo  repository.DataExportRepository
+	userRepo    repository.UserRepository
+	tripRepo    repository.TripRepository
+	bookingRepo repository.BookingRepository
+	blobs       storage.BlobStorage
+	notifier    NotificationService
+	db          *gorm.DB
+	logger      *slog.Logger
+}
+
+func NewAccountService(
+	accountRepo repository.AccountRepository,
+	exportRepo repository.DataExportRepository,
+	userRepo repository.UserRepository,
+	tripRepo repository.TripRepository,
+	bookingRepo repository.BookingRepository,
+	blobs storage.BlobStorage,
+	notifier NotificationService,
+	db *gorm.DB,
+	logger *slog.Logger,
+) AccountService {
+	return &accountService{
+		accountRepo: accountRepo,
+		exportRepo:  exportRepo,
+		userRepo:    userRepo,
+		tripRepo:    tripRepo,
+		bookingRepo: bookingRepo,
+		blobs:       blobs,
+		notifier:    notifier,
+		db:          db,
+		logger:      logger,
+	}
+}
+
+func (s *accountService) RequestExport(userID uint) (*models.DataExport, error) {
+	op := "service.account.request_export"
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	active, err := s.exportRepo.HasActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrExportInProgress
+	}
+
+	export := &models.DataExport{UserID: userID, Status: constants.ExportPending}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("data export requested",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("export_id", uint64(export.ID)),
+	)
+
+	return export, nil
+}
+
+func (s *accountService) ListExports(userID uint) ([]models.DataExport, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.exportRepo.ListByUser(userID)
+}
+
+func (s *accountService) GetExport(ctx context.Context, userID, exportID uint) (*dto.DataExportResponse, error) {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+
+	if export.UserID != userID {
+		return nil, ErrExportNotFound
+	}
+
+	resp := &dto.DataExportResponse{DataExport: *export}
+
+	if export.Status == constants.ExportReady && export.Key != "" {
+		url, err := s.blobs.SignedURL(ctx, export.Key, exportURLTTL)
+		if err != nil {
+			return nil, err
+		}
+		resp.DownloadURL = url
+	}
+
+	return resp, nil
+}
+
+func (s *accountService) ProcessExports(ctx context.Context, now time.Time) (int, error) {
+	op := "service.account.process_exports"
+
+	if _, err := s.exportRepo.ResetStale(now.Add(-exportStaleAfter)); err != nil {
+		return 0, err
+	}
+
+	if err := s.purgeExpired(ctx, now); err != nil {
+		return 0, err
+	}
+
+	exports, err := s.exportRepo.ClaimPending(exportBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+
+	for _, export := range exports {
+		if err := s.buildExport(ctx, export, now); err != nil {
+			s.logger.Error("data export failed",
+				slog.String("op", op),
+				slog.Uint64("export_id", uint64(export.ID)),
+				slog.Any("error", err),
+			)
+
+			if err := s.exportRepo.MarkFailed(export.ID, "failed to build archive"); err != nil {
+				return done, err
+			}
+			continue
+		}
+		done++
+	}
+
+	return done, nil
+}
+
+func (s *accountService) buildExport(ctx context.Context, export models.DataExport, now time.Time) error {
+	data, err := s.exportRepo.Collect(export.UserID)
+	if err != nil {
+		return err
+	}
+	data.ExportedAt = now.UTC()
+
+	archive, err := buildExportArchive(data)
+	if err != nil {
+		return err
+	}
+
+	key, err := storage.NewKey(fmt.Sprintf("users/%d/exports", export.UserID), ".zip")
+	if err != nil {
+		return err
+	}
+
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(archive), int64(len(archive)), "application/zip"); err != nil {
+		return err
+	}
+
+	if err := s.exportRepo.MarkReady(export.ID, key, int64(len(archive)), now, now.Add(exportTTL)); err != nil {
+		s.deleteBlobs(ctx, []string{key})
+		return err
+	}
+
+	return nil
+}
+
+func (s *accountService) purgeExpired(ctx context.Context, now time.Time) error {
+	expired, err := s.exportRepo.ListExpired(now)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range expired {
+		if err := s.blobs.Delete(ctx, export.Key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			s.logger.Error("failed to delete expired export",
+				slog.Uint64("export_id", uint64(export.ID)),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		if err := s.exportRepo.MarkExpired(export.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *accountService) Erase(ctx context.Context, actorID, userID uint, forfeitBalance bool) (*models.AccountErasure, error) {
+	op := "service.account.erase"
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	busy, err := s.accountRepo.HasTripInProgress(userID)
+	if err != nil {
+		return nil, err
+	}
+	if busy {
+		return nil, ErrTripInProgress
+	}
+
+	if user.Balance > 0 && !forfeitBalance {
+		return nil, fmt.Errorf("%w (balance: %d)", ErrOutstandingBalance, user.Balance)
+	}
+
+	// ключи собираем до удаления записей, а сами файлы удаляем после коммита:
+	// откатить удаление из хранилища нельзя
+	keys, err := s.accountRepo.BlobKeys(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	erasure := &models.AccountErasure{
+		UserID:           userID,
+		RequestedBy:      actorID,
+		ForfeitedBalance: user.Balance,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		accountRepo := s.accountRepo.WithDB(tx)
+		tripRepo := s.tripRepo.WithDB(tx)
+		bookingRepo := s.bookingRepo.WithDB(tx)
+
+		// будущие поездки водителя отменяются вместе со всеми заявками на них
+		trips, err := tripRepo.ListByDriver(userID, string(constants.TripPublished), 0)
+		if err != nil {
+			return err
+		}
+
+		for _, trip := range trips {
+			cancelled, err := tripRepo.MarkCancelled(trip.ID)
+			if err != nil {
+				return err
+			}
+			if !cancelled {
+				continue
+			}
+
+			cancelledBookings, err := bookingRepo.CancelActiveByTrip(trip.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, booking := range cancelledBookings {
+				if err := s.notifier.Enqueue(tx, booking.PassengerID, constants.NotificationTripCancelled, notify.TripData(&booking, &trip)); err != nil {
+					return err
+				}
+			}
+
+			erasure.CancelledTrips++
+			erasure.CancelledBookings += len(cancelledBookings)
+		}
+
+		// заявки пассажира отменяются с возвратом мест, как при обычной отмене
+		bookings, err := bookingRepo.ListActiveByPassenger(userID, now)
+		if err != nil {
+			return err
+		}
+
+		for i := range bookings {
+			booking := &bookings[i]
+
+			trip, err := tripRepo.GetByID(booking.TripID)
+			if err != nil {
+				return err
+			}
+
+			if booking.BookingStatus == constants.BookingApproved {
+				if err := tripRepo.ReleaseSeats(trip.ID, booking.Seats); err != nil {
+					return err
+				}
+			}
+
+			booking.BookingStatus = constants.BookingCancelled
+			if err := bookingRepo.Update(booking); err != nil {
+				return err
+			}
+
+			// уведомление лежит в outbox и после удаления — настоящее имя туда не пишем
+			data := notify.TripData(booking, trip)
+			data.PassengerName = models.FormerUserName
+
+			if err := s.notifier.Enqueue(tx, trip.DriverID, constants.NotificationBookingCancelled, data); err != nil {
+				return err
+			}
+		}
+
+		erasure.CancelledBookings += len(bookings)
+
+		if err := accountRepo.DeletePersonalData(userID); err != nil {
+			return err
+		}
+
+		if err := accountRepo.Anonymize(userID, now); err != nil {
+			return err
+		}
+
+		return accountRepo.CreateErasure(erasure)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteBlobs(ctx, keys)
+
+	s.logger.Info("account erased",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("actor_id", uint64(actorID)),
+		slog.Int("forfeited_balance", erasure.ForfeitedBalance),
+		slog.Int("cancelled_trips", erasure.CancelledTrips),
+		slog.Int("cancelled_bookings", erasure.CancelledBookings),
+	)
+
+	return erasure, nil
+}
+
+// deleteBlobs удаляет файлы; ошибки только логируются — записи о файлах уже удалены
+func (s *accountService) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			s.logger.Error("failed to delete blob", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"log/slog"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+type AuditService interface {
+	// Record сохраняет запись журнала; ошибка только логируется — действие уже выполнено
+	Record(entry *models.AuditEntry)
+
+	List(filter dto.AuditFilter) ([]models.AuditEntry, models.PageInfo, error)
+}
+
+type auditService struct {
+	repo   repository.AuditRepository
+	logger *slog.Logger
+}
+
+func NewAuditService(repo repository.AuditRepository, logger *slog.Logger) AuditService {
+	return &auditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *auditService) Record(entry *models.AuditEntry) {
+	if err := s.repo.Create(entry); err != nil {
+		s.logger.Error("failed to write audit entry",
+			slog.String("action", entry.Action),
+			slog.Uint64("actor_id", uint64(entry.ActorID)),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (s *auditService) List(filter dto.AuditFilter) ([]models.AuditEntry, models.PageInfo, error) {
+	return s.repo.List(filter)
+}
@@ -0,0 +1,493 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/auth"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/phone"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid phone or password")
+	ErrWrongPassword       = errors.New("current password is incorrect")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, the session is closed")
+	ErrSessionClosed       = errors.New("session is closed")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+const (
+	// sessionTouchInterval — как часто запросы обновляют «последнюю активность» сессии
+	sessionTouchInterval = time.Minute
+
+	maxDeviceNameLength = 100
+	maxUserAgentLength  = 255
+)
+
+// dummyPasswordHash сверяется, когда телефона нет в базе: ответ по времени
+// не должен выдавать, зарегистрирован ли номер
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := auth.HashPassword("dummy password")
+	return hash
+})
+
+type AuthService interface {
+	// Login открывает сессию нового устройства
+	Login(phone, password string, client dto.ClientInfo) (*dto.TokenResponse, error)
+
+	// Refresh меняет токен обновления на новую пару токенов. Повторно предъявленный
+	// токен означает, что его украли: сессия закрывается целиком
+	Refresh(refreshToken string, client dto.ClientInfo) (*dto.TokenResponse, error)
+
+	// Authenticate проверяет токен доступа и сессию и возвращает владельца с актуальной ролью
+	Authenticate(token string, client dto.ClientInfo) (*auth.Principal, error)
+
+	// Logout закрывает текущую сессию
+	Logout(p *auth.Principal) error
+
+	// LogoutAll закрывает все сессии пользователя, включая текущую
+	LogoutAll(userID uint) (int64, error)
+
+	ListSessions(userID, currentSessionID uint) ([]dto.SessionResponse, error)
+
+	// RevokeSession закрывает одно из устройств пользователя
+	RevokeSession(userID, sessionID uint) error
+
+	// ChangePassword меняет пароль и закрывает остальные сессии пользователя
+	ChangePassword(userID, sessionID uint, current, next string) error
+
+	// ResetPassword — пароль, заданный администратором; закрывает все сессии
+	ResetPassword(userID uint, password string) error
+
+	// EnsureAdmins выдаёт роль администратора пользователям с указанными телефонами
+	EnsureAdmins(phones []string) (int, error)
+}
+
+type authService struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	tokens      *auth.Tokens
+	db          *gorm.DB
+	logger      *slog.Logger
+}
+
+func NewAuthService(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	tokens *auth.Tokens,
+	db *gorm.DB,
+	logger *slog.Logger,
+) AuthService {
+	return &authService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokens:      tokens,
+		db:          db,
+		logger:      logger,
+	}
+}
+
+func (s *authService) Login(rawPhone, password string, client dto.ClientInfo) (*dto.TokenResponse, error) {
+	op := "service.auth.login"
+
+	normalized, err := phone.Normalize(rawPhone)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.userRepo.GetByPhone(normalized)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			auth.CheckPassword(dummyPasswordHash(), password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		s.logger.Warn("failed login attempt", slog.String("op", op), slog.Uint64("user_id", uint64(user.ID)))
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+
+	deviceName := client.DeviceName
+	if deviceName == "" {
+		deviceName = client.UserAgent
+	}
+	if deviceName == "" {
+		deviceName = "unknown device"
+	}
+
+	session := &models.Session{
+		UserID:     user.ID,
+		DeviceName: truncateRunes(deviceName, maxDeviceNameLength),
+		IP:         client.IP,
+		UserAgent:  truncateRunes(client.UserAgent, maxUserAgentLength),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.tokens.RefreshTTL()),
+	}
+
+	var resp *dto.TokenResponse
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithDB(tx)
+
+		err := sessionRepo.Create(session)
+		if err != nil {
+			return err
+		}
+
+		resp, err = s.issue(sessionRepo, user.ID, session.ID, now)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user logged in",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(user.ID)),
+		slog.Uint64("session_id", uint64(session.ID)),
+	)
+
+	return resp, nil
+}
+
+func (s *authService) Refresh(refreshToken string, client dto.ClientInfo) (*dto.TokenResponse, error) {
+	op := "service.auth.refresh"
+
+	now := time.Now()
+
+	stored, err := s.sessionRepo.GetRefreshToken(auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	var (
+		resp   *dto.TokenResponse
+		reused bool
+	)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithDB(tx)
+
+		session, err := sessionRepo.GetByID(stored.SessionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		if !session.Active(now) || !now.Before(stored.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		fresh, err := sessionRepo.UseRefreshToken(stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			reused = true
+			return nil
+		}
+
+		expiresAt := now.Add(s.tokens.RefreshTTL())
+		if err := sessionRepo.Extend(session.ID, now, expiresAt, client.IP, truncateRunes(client.UserAgent, maxUserAgentLength)); err != nil {
+			return err
+		}
+
+		resp, err = s.issue(sessionRepo, session.UserID, session.ID, now)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// токен уже меняли: им пользуется кто-то ещё, поэтому закрываем всю сессию —
+	// и у вора, и у владельца, который войдёт заново
+	if reused {
+		if _, err := s.sessionRepo.Revoke(stored.SessionID, constants.SessionTokenReuse, now); err != nil {
+			return nil, err
+		}
+
+		s.logger.Warn("refresh token reuse detected, session revoked",
+			slog.String("op", op),
+			slog.Uint64("session_id", uint64(stored.SessionID)),
+			slog.String("ip", client.IP),
+		)
+		return nil, ErrRefreshTokenReused
+	}
+
+	return resp, nil
+}
+
+// issue выпускает токен доступа и новый токен обновления для сессии
+func (s *authService) issue(sessionRepo repository.SessionRepository, userID, sessionID uint, now time.Time) (*dto.TokenResponse, error) {
+	refreshToken, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &models.RefreshToken{
+		SessionID: sessionID,
+		Hash:      auth.HashRefreshToken(refreshToken),
+		ExpiresAt: now.Add(s.tokens.RefreshTTL()),
+	}
+
+	if err := sessionRepo.CreateRefreshToken(stored); err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt := s.tokens.Issue(userID, sessionID, now)
+
+	return &dto.TokenResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt.UTC(),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt.UTC(),
+		SessionID:        sessionID,
+	}, nil
+}
+
+func (s *authService) Authenticate(token string, client dto.ClientInfo) (*auth.Principal, error) {
+	now := time.Now()
+
+	claims, err := s.tokens.Parse(token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSessionClosed
+		}
+		return nil, err
+	}
+
+	if session.UserID != claims.UserID || !session.Active(now) {
+		return nil, ErrSessionClosed
+	}
+
+	// не пишем в базу на каждый запрос — точности до минуты хватает
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := s.sessionRepo.Touch(session.ID, now, client.IP); err != nil {
+			return nil, err
+		}
+	}
+
+	// удалённый пользователь теряет доступ сразу, не дожидаясь истечения токена
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, auth.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	p := principalOf(user)
+	p.SessionID = session.ID
+
+	return p, nil
+}
+
+func (s *authService) Logout(p *auth.Principal) error {
+	op := "service.auth.logout"
+
+	if _, err := s.sessionRepo.Revoke(p.SessionID, constants.SessionLogout, time.Now()); err != nil {
+		return err
+	}
+
+	s.logger.Info("user logged out",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(p.UserID)),
+		slog.Uint64("session_id", uint64(p.SessionID)),
+	)
+	return nil
+}
+
+func (s *authService) LogoutAll(userID uint) (int64, error) {
+	op := "service.auth.logout_all"
+
+	count, err := s.sessionRepo.RevokeAll(userID, 0, constants.SessionLogoutAll, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("user logged out everywhere",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int64("sessions", count),
+	)
+	return count, nil
+}
+
+func (s *authService) ListSessions(userID, currentSessionID uint) ([]dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActive(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, dto.SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *authService) RevokeSession(userID, sessionID uint) error {
+	op := "service.auth.revoke_session"
+
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	// чужая сессия для пользователя не существует
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	revoked, err := s.sessionRepo.Revoke(sessionID, constants.SessionRevoked, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+
+	s.logger.Info("session revoked",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("session_id", uint64(sessionID)),
+	)
+	return nil
+}
+
+func (s *authService) ChangePassword(userID, sessionID uint, current, next string) error {
+	op := "service.auth.change_password"
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, current) {
+		return ErrWrongPassword
+	}
+
+	if err := s.setPassword(userID, sessionID, next); err != nil {
+		return err
+	}
+
+	s.logger.Info("password changed", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+	return nil
+}
+
+func (s *authService) ResetPassword(userID uint, password string) error {
+	op := "service.auth.reset_password"
+
+	if err := s.setPassword(userID, 0, password); err != nil {
+		return err
+	}
+
+	s.logger.Info("password reset", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+	return nil
+}
+
+func (s *authService) EnsureAdmins(phones []string) (int, error) {
+	op := "service.auth.ensure_admins"
+
+	count := 0
+
+	for _, raw := range phones {
+		normalized, err := phone.Normalize(raw)
+		if err != nil {
+			s.logger.Warn("invalid admin phone", slog.String("op", op), slog.String("phone", raw))
+			continue
+		}
+
+		user, err := s.userRepo.GetByPhone(normalized)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				s.logger.Warn("admin phone is not registered", slog.String("op", op), slog.String("phone", normalized))
+				continue
+			}
+			return count, err
+		}
+
+		if user.Role == constants.RoleAdmin {
+			continue
+		}
+
+		if err := s.userRepo.SetRole(user.ID, constants.RoleAdmin); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// setPassword меняет пароль и закрывает все сессии, кроме keepSessionID
+func (s *authService) setPassword(userID, keepSessionID uint, password string) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithDB(tx).SetPasswordHash(userID, hash); err != nil {
+			return err
+		}
+
+		_, err := s.sessionRepo.WithDB(tx).RevokeAll(userID, keepSessionID, constants.SessionPasswordChanged, time.Now())
+		return err
+	})
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+func principalOf(user *models.User) *auth.Principal {
+	role := user.Role
+	if role == "" {
+		role = constants.RolePassenger
+	}
+
+	return &auth.Principal{UserID: user.ID, Role: role}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/notify"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotBlockSelf = errors.New("users cannot block themselves")
+	ErrAlreadyBlocked  = errors.New("user is already blocked")
+	ErrNotBlocked      = errors.New("user is not blocked")
+)
+
+type BlockService interface {
+	// Block блокирует пользователя и отменяет будущие заявки между парой
+	Block(blockerID, blockedID uint) (*dto.BlockResponse, error)
+
+	Unblock(blockerID, blockedID uint) error
+
+	List(blockerID uint) ([]models.UserBlock, error)
+}
+
+type blockService struct {
+	blockRepo   repository.BlockRepository
+	bookingRepo repository.BookingRepository
+	tripRepo    repository.TripRepository
+	userRepo    repository.UserRepository
+	notifier    NotificationService
+	db          *gorm.DB
+	logger      *slog.Logger
+}
+
+func NewBlockService(
+	blockRepo repository.BlockRepository,
+	bookingRepo repository.BookingRepository,
+	tripRepo repository.TripRepository,
+	userRepo repository.UserRepository,
+	notifier NotificationService,
+	db *gorm.DB,
+	logger *slog.Logger,
+) BlockService {
+	return &blockService{
+		blockRepo:   blockRepo,
+		bookingRepo: bookingRepo,
+		tripRepo:    tripRepo,
+		userRepo:    userRepo,
+		notifier:    notifier,
+		db:          db,
+		logger:      logger,
+	}
+}
+
+func (s *blockService) Block(blockerID, blockedID uint) (*dto.BlockResponse, error) {
+	op := "service.block.block"
+
+	if blockerID == blockedID {
+		return nil, ErrCannotBlockSelf
+	}
+
+	blocker, err := s.userRepo.GetByID(blockerID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByID(blockedID); err != nil {
+		return nil, err
+	}
+
+	resp := &dto.BlockResponse{BlockerID: blockerID, BlockedID: blockedID}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		blockRepo := s.blockRepo.WithDB(tx)
+		bookingRepo := s.bookingRepo.WithDB(tx)
+		tripRepo := s.tripRepo.WithDB(tx)
+
+		created, err := blockRepo.Create(blockerID, blockedID)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrAlreadyBlocked
+		}
+
+		bookings, err := bookingRepo.ListActiveBetween(blockerID, blockedID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for i := range bookings {
+			booking := &bookings[i]
+
+			trip, err := tripRepo.GetByID(booking.TripID)
+			if err != nil {
+				return err
+			}
+
+			// места возвращаются только за одобренные заявки — ожидающие их не занимали
+			if booking.BookingStatus == constants.BookingApproved {
+				if err := tripRepo.ReleaseSeats(trip.ID, booking.Seats); err != nil {
+					return err
+				}
+			}
+
+			booking.BookingStatus = constants.BookingCancelled
+			if err := bookingRepo.Update(booking); err != nil {
+				return err
+			}
+
+			// уведомляем вторую сторону так же, как при обычной отмене или отказе,
+			// не сообщая о блокировке
+			data := notify.TripData(booking, trip)
+			if booking.PassengerID == blockerID {
+				data.PassengerName = blocker.Name
+				err = s.notifier.Enqueue(tx, trip.DriverID, constants.NotificationBookingCancelled, data)
+			} else {
+				err = s.notifier.Enqueue(tx, booking.PassengerID, constants.NotificationBookingRejected, data)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		resp.CancelledBookings = len(bookings)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user blocked",
+		slog.String("op", op),
+		slog.Uint64("blocker_id", uint64(blockerID)),
+		slog.Uint64("blocked_id", uint64(blockedID)),
+		slog.Int("cancelled_bookings", resp.CancelledBookings),
+	)
+
+	return resp, nil
+}
+
+func (s *blockService) Unblock(blockerID, blockedID uint) error {
+	op := "service.block.unblock"
+
+	deleted, err := s.blockRepo.Delete(blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotBlocked
+	}
+
+	s.logger.Info("user unblocked",
+		slog.String("op", op),
+		slog.Uint64("blocker_id", uint64(blockerID)),
+		slog.Uint64("blocked_id", uint64(blockedID)),
+	)
+
+	return nil
+}
+
+func (s *blockService) List(blockerID uint) ([]models.UserBlock, error) {
+	if _, err := s.userRepo.GetByID(blockerID); err != nil {
+		return nil, err
+	}
+
+	return s.blockRepo.ListByBlocker(blockerID)
+}
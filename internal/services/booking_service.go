@@ -4,18 +4,35 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/notify"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrWomenOnlyTrip     = errors.New("trip is available to women only")
+	ErrNotEnoughSeats    = errors.New("not enough available seats")
+	ErrNotBookingOwner   = errors.New("booking belongs to another passenger")
+	ErrCannotCancel      = errors.New("booking can no longer be cancelled")
+	ErrBookingNotInTrip  = errors.New("booking does not belong to this trip")
+	ErrCheckInClosed     = errors.New("check-in is not open for this trip")
+	ErrCannotCheckIn     = errors.New("only approved passengers who are not checked in can be marked")
+	ErrNoShowTooEarly    = errors.New("no-show can be marked only after the trip has started")
+	ErrCannotMarkNoShow  = errors.New("only approved passengers who are not checked in can be marked as no-show")
+	ErrBookingNotPending = errors.New("booking is not pending")
+	ErrUserBlocked       = errors.New("booking is not possible: one of the users has blocked the other")
+	ErrTripNotBookable   = errors.New("trip is not open for booking")
+)
+
 type BookingService interface {
 	Create(req *dto.BookingCreateRequest) (*models.Booking, error)
 
-	List(filter models.Page) ([]models.Booking, error)
+	List(filter models.Page) ([]models.Booking, models.PageInfo, error)
 
 	Approve(bookingID uint, driverID uint) error
 
@@ -23,31 +40,54 @@ type BookingService interface {
 
 	GetByID(id uint) (*models.Booking, error)
 
-	GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error)
+	GetAllPendingBookingsByTripID(driverID, tripID uint) ([]dto.PendingBooking, error)
 
 	Update(id uint, req *dto.BookingUpdateRequest) (*models.Booking, error)
 
 	Delete(id uint) error
+
+	ListByPassenger(filter dto.PassengerBookingFilter) ([]dto.PassengerBooking, models.PageInfo, error)
+
+	Cancel(bookingID uint, passengerID uint) (*dto.BookingCancelResponse, error)
+
+	CheckIn(tripID, bookingID, driverID uint) error
+
+	MarkNoShow(tripID, bookingID, driverID uint) error
+
+	// ExpirePending отклоняет заявки, которые водитель не рассмотрел до отправления
+	ExpirePending(tripID uint) (int64, error)
 }
 
 type bookingService struct {
-	bookingRepo repository.BookingRepository
-	tripRepo    repository.TripRepository
-	db          *gorm.DB
-	logger      *slog.Logger
+	bookingRepo  repository.BookingRepository
+	tripRepo     repository.TripRepository
+	userRepo     repository.UserRepository
+	blockRepo    repository.BlockRepository
+	reviewPolicy ReviewPolicy
+	notifier     NotificationService
+	db           *gorm.DB
+	logger       *slog.Logger
 }
 
 func NewBookingService(
 	bookingRepo repository.BookingRepository,
 	tripRepo repository.TripRepository,
+	userRepo repository.UserRepository,
+	blockRepo repository.BlockRepository,
+	reviewPolicy ReviewPolicy,
+	notifier NotificationService,
 	db *gorm.DB,
 	logger *slog.Logger,
 ) BookingService {
 	return &bookingService{
-		bookingRepo: bookingRepo,
-		tripRepo:    tripRepo,
-		db:          db,
-		logger:      logger,
+		bookingRepo:  bookingRepo,
+		tripRepo:     tripRepo,
+		userRepo:     userRepo,
+		blockRepo:    blockRepo,
+		reviewPolicy: reviewPolicy,
+		notifier:     notifier,
+		db:           db,
+		logger:       logger,
 	}
 }
 
@@ -58,13 +98,74 @@ func (s *bookingService) Create(
 
 	s.logger.Debug(" call", slog.String("op", op))
 
+	trip, err := s.tripRepo.GetByID(req.TripID)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	// начатые, завершённые и отменённые поездки (в том числе при удалении аккаунта водителя)
+	// заявок не принимают, даже если места в них формально остались
+	if !tripBookable(trip, time.Now()) {
+		return nil, ErrTripNotBookable
+	}
+
+	passenger, err := s.userRepo.GetByID(req.PassengerID)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	blocked, err := s.blockRepo.IsBlocked(passenger.ID, trip.DriverID)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	if blocked {
+		s.logger.Warn("booking between blocked users rejected",
+			slog.String("op", op),
+			slog.Uint64("trip_id", uint64(trip.ID)),
+			slog.Uint64("passenger_id", uint64(passenger.ID)),
+		)
+		return nil, ErrUserBlocked
+	}
+
+	if trip.Preferences.WomenOnly && passenger.Gender != constants.GenderFemale {
+		s.logger.Warn("women-only trip booking rejected",
+			slog.String("op", op),
+			slog.Uint64("trip_id", uint64(trip.ID)),
+			slog.Uint64("passenger_id", uint64(passenger.ID)),
+		)
+		return nil, ErrWomenOnlyTrip
+	}
+
+	seats := req.Seats
+	if seats == 0 {
+		seats = 1
+	}
+
+	if seats > trip.AvailableSeats {
+		return nil, ErrNotEnoughSeats
+	}
+
 	booking := &models.Booking{
 		TripID:        req.TripID,
 		PassengerID:   req.PassengerID,
+		Seats:         seats,
 		BookingStatus: constants.BookingPending,
 	}
 
-	if err := s.bookingRepo.Create(booking); err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.bookingRepo.WithDB(tx).Create(booking); err != nil {
+			return err
+		}
+
+		data := notify.TripData(booking, trip)
+		data.PassengerName = passenger.Name
+
+		return s.notifier.Enqueue(tx, trip.DriverID, constants.NotificationBookingCreated, data)
+	})
+	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
@@ -83,7 +184,7 @@ func (s *bookingService) Approve(bookingID, driverID uint) error {
 		}
 
 		if booking.BookingStatus != constants.BookingPending {
-			return errors.New("booking is not pending")
+			return ErrBookingNotPending
 		}
 
 		trip, err := tripRepo.GetByID(booking.TripID)
@@ -92,28 +193,32 @@ func (s *bookingService) Approve(bookingID, driverID uint) error {
 		}
 
 		if trip.DriverID != driverID {
-			return errors.New("forbidden")
+			return ErrNotTripDriver
 		}
 
-		if trip.AvailableSeats <= 0 {
-			return errors.New("no available seats")
+		if !tripBookable(trip, time.Now()) {
+			return ErrTripNotBookable
 		}
 
-		// Водитель одобряет
-		trip.AvailableSeats--
-		booking.BookingStatus = constants.BookingApproved
-
-		err = tripRepo.Update(trip)
+		// Водитель одобряет: места списываются одним UPDATE с проверкой остатка,
+		// чтобы параллельные одобрения не продали лишнее
+		reserved, err := tripRepo.ReserveSeats(trip.ID, booking.Seats)
 		if err != nil {
 			return err
 		}
+		if !reserved {
+			return ErrNotEnoughSeats
+		}
+
+		trip.AvailableSeats -= booking.Seats
+		booking.BookingStatus = constants.BookingApproved
 
 		err = bookingRepo.Update(booking)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return s.notifier.Enqueue(tx, booking.PassengerID, constants.NotificationBookingApproved, notify.TripData(booking, trip))
 	})
 }
 
@@ -133,11 +238,11 @@ func (s *bookingService) Rejected(bookingID uint, driverID uint) error {
 		}
 
 		if trip.DriverID != driverID {
-			return errors.New("forbidden")
+			return ErrNotTripDriver
 		}
 
 		if booking.BookingStatus != constants.BookingPending {
-			return errors.New("booking is not pending")
+			return ErrBookingNotPending
 		}
 
 		booking.BookingStatus = constants.BookingRejected
@@ -146,38 +251,51 @@ func (s *bookingService) Rejected(bookingID uint, driverID uint) error {
 			return err
 		}
 
-		return nil
+		return s.notifier.Enqueue(tx, booking.PassengerID, constants.NotificationBookingRejected, notify.TripData(booking, trip))
 	})
 }
 
-func (s *bookingService) GetAllPendingBookingsByTripID(driverID, tripID uint) ([]models.Booking, error) {
+func (s *bookingService) GetAllPendingBookingsByTripID(driverID, tripID uint) ([]dto.PendingBooking, error) {
 
 	op := "service.booking.GetAllPendingBookingsByTripID"
 
 	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("trip_id", uint64(tripID)))
 
-	bookings, err := s.bookingRepo.GetAllPendingBookingsByTripID(driverID, tripID)
+	rows, err := s.bookingRepo.GetAllPendingBookingsByTripID(driverID, tripID)
 	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
+
+	bookings := make([]dto.PendingBooking, 0, len(rows))
+	for _, row := range rows {
+		bookings = append(bookings, dto.PendingBooking{
+			Booking:       row.Booking,
+			PassengerName: row.PassengerName,
+			PassengerRating: dto.PassengerRatingInfo{
+				Count:   row.PassengerReviews,
+				Average: row.PassengerRating,
+			},
+		})
+	}
+
 	s.logger.Info("pending bookings retrieved", slog.String("op", op), slog.Int("count", len(bookings)))
 	return bookings, nil
 }
 
-func (s *bookingService) List(filter models.Page) ([]models.Booking, error) {
+func (s *bookingService) List(filter models.Page) ([]models.Booking, models.PageInfo, error) {
 
 	op := "service.booking.list"
 
 	s.logger.Debug(" call", slog.String("op", op))
 
-	bookings, err := s.bookingRepo.List(filter)
+	bookings, info, err := s.bookingRepo.List(filter)
 	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 	s.logger.Info("bookings listed", slog.String("op", op), slog.Int("count", len(bookings)))
-	return bookings, nil
+	return bookings, info, nil
 }
 
 func (s *bookingService) GetByID(id uint) (*models.Booking, error) {
@@ -227,3 +345,267 @@ func (s *bookingService) Delete(id uint) error {
 	s.logger.Info("booking deleted", slog.String("op", op), slog.Uint64("booking_id", uint64(id)))
 	return nil
 }
+
+func (s *bookingService) ListByPassenger(filter dto.PassengerBookingFilter) ([]dto.PassengerBooking, models.PageInfo, error) {
+	op := "service.booking.ListByPassenger"
+
+	s.logger.Debug(" call", slog.String("op", op), slog.Uint64("passenger_id", uint64(filter.PassengerID)))
+
+	rows, info, err := s.bookingRepo.ListByPassenger(filter)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	now := time.Now()
+	result := make([]dto.PassengerBooking, 0, len(rows))
+
+	for _, row := range rows {
+		result = append(result, dto.PassengerBooking{
+			Booking: row.Booking,
+			Trip: dto.BookingTripInfo{
+				ID:          row.TripID,
+				FromCity:    row.FromCity,
+				ToCity:      row.ToCity,
+				StartTime:   row.StartTime,
+				DurationMin: row.DurationMin,
+				Price:       row.Price,
+				TripStatus:  row.TripStatus,
+				DriverID:    row.DriverID,
+				DriverName:  row.DriverName,
+				Car: dto.BookingCarInfo{
+					Brand:        row.CarBrand,
+					CarModel:     row.CarModel,
+					Color:        row.CarColor,
+					LicensePlate: row.CarLicensePlate,
+				},
+			},
+			CanCancel:     canCancelBooking(row.BookingStatus, row.TripStatus, row.StartTime, now),
+			RefundPercent: bookingRefundPercent(row.Booking, row.StartTime, now),
+			CanReview: row.TripStatus == string(constants.TripCompleted) &&
+				!row.HasReview &&
+				bookingReviewError(&row.Booking, row.TripHasCheckIns) == nil &&
+				s.reviewPolicy.WindowOpen(finishedAt(row.FinishedAt, row.StartTime, row.DurationMin), now),
+		})
+	}
+
+	s.logger.Info("passenger bookings listed", slog.String("op", op), slog.Int("count", len(result)))
+	return result, info, nil
+}
+
+// Cancel отменяет заявку пассажира; для одобренной заявки места возвращаются в поездку
+func (s *bookingService) Cancel(bookingID uint, passengerID uint) (*dto.BookingCancelResponse, error) {
+	op := "service.booking.Cancel"
+
+	var resp *dto.BookingCancelResponse
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		bookingRepo := s.bookingRepo.WithDB(tx)
+		tripRepo := s.tripRepo.WithDB(tx)
+
+		booking, err := bookingRepo.GetByID(bookingID)
+		if err != nil {
+			return err
+		}
+
+		if booking.PassengerID != passengerID {
+			return ErrNotBookingOwner
+		}
+
+		trip, err := tripRepo.GetByID(booking.TripID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		if !canCancelBooking(booking.BookingStatus, trip.TripStatus, trip.StartTime, now) {
+			return ErrCannotCancel
+		}
+
+		if booking.BookingStatus == constants.BookingApproved {
+			if err := tripRepo.ReleaseSeats(trip.ID, booking.Seats); err != nil {
+				return err
+			}
+			trip.AvailableSeats += booking.Seats
+		}
+
+		booking.BookingStatus = constants.BookingCancelled
+		if err := bookingRepo.Update(booking); err != nil {
+			return err
+		}
+
+		passenger, err := s.userRepo.WithDB(tx).GetByID(passengerID)
+		if err != nil {
+			return err
+		}
+
+		data := notify.TripData(booking, trip)
+		data.PassengerName = passenger.Name
+
+		if err := s.notifier.Enqueue(tx, trip.DriverID, constants.NotificationBookingCancelled, data); err != nil {
+			return err
+		}
+
+		resp = &dto.BookingCancelResponse{
+			BookingID:     booking.ID,
+			Status:        booking.BookingStatus,
+			RefundPercent: refundPercent(booking.BookingStatus, trip.StartTime, now),
+			Informational: true,
+		}
+
+		s.logger.Info("booking cancelled", slog.String("op", op), slog.Uint64("booking_id", uint64(bookingID)))
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CheckIn отмечает посадку одобренного пассажира; доступно водителю поездки
+// в окне перед отправлением и во время поездки
+func (s *bookingService) CheckIn(tripID, bookingID, driverID uint) error {
+	op := "service.booking.CheckIn"
+
+	booking, trip, err := s.driverBooking(tripID, bookingID, driverID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	open := trip.TripStatus == string(constants.TripInProgress) ||
+		(trip.TripStatus == string(constants.TripPublished) && !now.Before(trip.StartTime.Add(-tripEarlyStartWindow)))
+	if !open {
+		return ErrCheckInClosed
+	}
+
+	ok, err := s.bookingRepo.CheckIn(booking.ID, now)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	if !ok {
+		return ErrCannotCheckIn
+	}
+
+	s.logger.Info("passenger checked in", slog.String("op", op), slog.Uint64("booking_id", uint64(bookingID)))
+	return nil
+}
+
+// MarkNoShow помечает не пришедшего пассажира; такой пассажир теряет право на отзыв и возврат
+func (s *bookingService) MarkNoShow(tripID, bookingID, driverID uint) error {
+	op := "service.booking.MarkNoShow"
+
+	booking, trip, err := s.driverBooking(tripID, bookingID, driverID)
+	if err != nil {
+		return err
+	}
+
+	if trip.TripStatus == string(constants.TripPublished) {
+		return ErrNoShowTooEarly
+	}
+
+	ok, err := s.bookingRepo.MarkNoShow(booking.ID)
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return err
+	}
+	if !ok {
+		return ErrCannotMarkNoShow
+	}
+
+	s.logger.Info("passenger marked as no-show", slog.String("op", op), slog.Uint64("booking_id", uint64(bookingID)))
+	return nil
+}
+
+func (s *bookingService) driverBooking(tripID, bookingID, driverID uint) (*models.Booking, *models.Trip, error) {
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if trip.DriverID != driverID {
+		return nil, nil, ErrNotTripDriver
+	}
+
+	booking, err := s.bookingRepo.GetByID(bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if booking.TripID != trip.ID {
+		return nil, nil, ErrBookingNotInTrip
+	}
+
+	return booking, trip, nil
+}
+
+// tripBookable — поездка опубликована и ещё не отправилась: до перевода воркером
+// в in_progress статус остаётся published и после времени отправления
+func tripBookable(trip *models.Trip, now time.Time) bool {
+	return trip.TripStatus == string(constants.TripPublished) && trip.StartTime.After(now)
+}
+
+func canCancelBooking(status constants.BookingStatus, tripStatus string, startTime, now time.Time) bool {
+	if status != constants.BookingPending && status != constants.BookingApproved {
+		return false
+	}
+
+	return tripStatus == string(constants.TripPublished) && startTime.After(now)
+}
+
+func (s *bookingService) ExpirePending(tripID uint) (int64, error) {
+	op := "service.booking.ExpirePending"
+
+	var count int64
+
+	// отказ и уведомления пассажирам пишутся в одной транзакции, как при ручном отказе
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		trip, err := s.tripRepo.WithDB(tx).GetByID(tripID)
+		if err != nil {
+			return err
+		}
+
+		rejected, err := s.bookingRepo.WithDB(tx).RejectPendingByTrip(tripID)
+		if err != nil {
+			return err
+		}
+
+		for i := range rejected {
+			booking := &rejected[i]
+			if err := s.notifier.Enqueue(tx, booking.PassengerID, constants.NotificationBookingRejected, notify.TripData(booking, trip)); err != nil {
+				return err
+			}
+		}
+
+		count = int64(len(rejected))
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	if count > 0 {
+		s.logger.Info("pending bookings expired",
+			slog.String("op", op),
+			slog.Uint64("trip_id", uint64(tripID)),
+			slog.Int64("count", count),
+		)
+	}
+
+	return count, nil
+}
+
+// bookingRefundPercent для отменённой заявки считает возврат на момент отмены (updated_at),
+// для активной — на текущий момент
+func bookingRefundPercent(booking models.Booking, startTime, now time.Time) int {
+	if booking.BookingStatus == constants.BookingCancelled {
+		return refundPercent(booking.BookingStatus, startTime, booking.UpdatedAt)
+	}
+
+	return refundPercent(booking.BookingStatus, startTime, now)
+}
@@ -1,18 +1,40 @@
 package services
 
 import (
+	"errors"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+var (
+	ErrInvalidLicensePlate = errors.New("invalid license plate")
+	ErrLicensePlateTaken   = errors.New("license plate already registered")
+	ErrInvalidCarYear      = errors.New("invalid car year")
+	ErrInvalidCarBodyType  = errors.New("invalid car body type")
+	ErrInvalidTrunkVolume  = errors.New("invalid trunk capacity")
+)
+
+// minCarYear — нижняя граница года выпуска, всё что раньше считаем опечаткой
+const minCarYear = 1950
+
+// plateLookalikes переводит кириллические буквы российских номеров в латинские двойники,
+// чтобы "А123ВС" и "A123BC" считались одним номером
+var plateLookalikes = map[rune]rune{
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H',
+	'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'У': 'Y', 'Х': 'X',
+}
+
 type CarService interface {
 	Create(id uint, req dto.CarCreateRequest) (*models.Car, error)
 
-	List(filter models.Page) ([]models.Car, error)
+	List(filter models.Page) ([]models.Car, models.PageInfo, error)
 
 	GetByOwner(id uint) (*models.Car, error)
 
@@ -45,16 +67,46 @@ func (s *carService) Create(id uint, req dto.CarCreateRequest) (*models.Car, err
 	}
 
 	car := models.Car{
-		OwnerID:  driver.ID,
-		Brand:    req.Brand,
-		CarModel: req.CarModel,
-		Seats:    req.Seats,
+		OwnerID:          driver.ID,
+		Brand:            req.Brand,
+		CarModel:         req.CarModel,
+		Seats:            req.Seats,
+		Color:            strings.TrimSpace(req.Color),
+		Year:             req.Year,
+		BodyType:         req.BodyType,
+		AirConditioning:  req.AirConditioning,
+		ChildSeat:        req.ChildSeat,
+		TrunkCapacityL:   req.TrunkCapacityL,
+		WheelchairAccess: req.WheelchairAccess,
+	}
+
+	if req.LicensePlate != "" {
+		plate, err := s.checkLicensePlate(req.LicensePlate, 0)
+		if err != nil {
+			return nil, err
+		}
+		car.LicensePlate = plate
 	}
 
+	if err := validateCarDetails(&car); err != nil {
+		return nil, err
+	}
+
+	// проверка номера выше не защищает от двух одновременных запросов: их разводит уникальный индекс
 	if err := s.carRepo.Create(&car); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrLicensePlateTaken
+		}
 		return nil, err
 	}
 
+	// с автомобилем пассажир становится водителем и может публиковать поездки
+	if driver.Role == constants.RolePassenger {
+		if err := s.userRepo.SetRole(driver.ID, constants.RoleDriver); err != nil {
+			return nil, err
+		}
+	}
+
 	return &car, nil
 }
 
@@ -77,13 +129,13 @@ func (s *carService) GetByID(id uint) (*models.Car, error) {
 	return car, nil
 }
 
-func (s *carService) List(filter models.Page) ([]models.Car, error) {
-	cars, err := s.carRepo.List(filter)
+func (s *carService) List(filter models.Page) ([]models.Car, models.PageInfo, error) {
+	cars, info, err := s.carRepo.List(filter)
 	if err != nil {
 		s.logger.Error("Ошибка при получении списка автомобилей", slog.String("error", err.Error()))
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
-	return cars, nil
+	return cars, info, nil
 }
 
 func (s *carService) Update(id uint, req dto.CarUpdateRequest) (*models.Car, error) {
@@ -102,8 +154,48 @@ func (s *carService) Update(id uint, req dto.CarUpdateRequest) (*models.Car, err
 	if req.Seats != nil {
 		car.Seats = *req.Seats
 	}
+	if req.LicensePlate != nil {
+		plate, err := s.checkLicensePlate(*req.LicensePlate, car.ID)
+		if err != nil {
+			return nil, err
+		}
+		// СТС выписано на старый номер — проверку нужно пройти заново
+		if plate != car.LicensePlate {
+			car.Verified = false
+			car.VerifiedAt = nil
+		}
+		car.LicensePlate = plate
+	}
+	if req.Color != nil {
+		car.Color = strings.TrimSpace(*req.Color)
+	}
+	if req.Year != nil {
+		car.Year = *req.Year
+	}
+	if req.BodyType != nil {
+		car.BodyType = *req.BodyType
+	}
+	if req.AirConditioning != nil {
+		car.AirConditioning = *req.AirConditioning
+	}
+	if req.ChildSeat != nil {
+		car.ChildSeat = *req.ChildSeat
+	}
+	if req.TrunkCapacityL != nil {
+		car.TrunkCapacityL = *req.TrunkCapacityL
+	}
+	if req.WheelchairAccess != nil {
+		car.WheelchairAccess = *req.WheelchairAccess
+	}
+
+	if err := validateCarDetails(car); err != nil {
+		return nil, err
+	}
 
 	updatedCar, err := s.carRepo.Update(car)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrLicensePlateTaken
+	}
 	if err != nil {
 		s.logger.Error("Ошибка при обновлении автомобиля", slog.Uint64("car_id", uint64(id)), slog.String("error", err.Error()))
 		return nil, err
@@ -127,3 +219,66 @@ func (s *carService) Delete(id uint) error {
 	s.logger.Info("Автомобиль успешно удалён", slog.Uint64("car_id", uint64(id)))
 	return nil
 }
+
+// checkLicensePlate нормализует номер и проверяет, что он не занят другим активным автомобилем
+func (s *carService) checkLicensePlate(raw string, carID uint) (string, error) {
+	plate, ok := NormalizeLicensePlate(raw)
+	if !ok {
+		return "", ErrInvalidLicensePlate
+	}
+
+	taken, err := s.carRepo.ExistsByLicensePlate(plate, carID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		s.logger.Warn("Номер уже зарегистрирован", slog.String("license_plate", plate))
+		return "", ErrLicensePlateTaken
+	}
+
+	return plate, nil
+}
+
+// NormalizeLicensePlate приводит номер к верхнему регистру, убирает пробелы и дефисы
+// и заменяет кириллицу латинскими двойниками
+func NormalizeLicensePlate(raw string) (string, bool) {
+	var b strings.Builder
+
+	for _, r := range strings.ToUpper(raw) {
+		switch {
+		case r == ' ' || r == '-' || r == '\t':
+			continue
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			latin, ok := plateLookalikes[r]
+			if !ok {
+				return "", false
+			}
+			b.WriteRune(latin)
+		}
+	}
+
+	plate := b.String()
+	if len(plate) < 4 || len(plate) > 12 {
+		return "", false
+	}
+
+	return plate, true
+}
+
+func validateCarDetails(car *models.Car) error {
+	if car.Year != 0 && (car.Year < minCarYear || car.Year > time.Now().Year()+1) {
+		return ErrInvalidCarYear
+	}
+
+	if car.BodyType != "" && !car.BodyType.IsValid() {
+		return ErrInvalidCarBodyType
+	}
+
+	if car.TrunkCapacityL < 0 {
+		return ErrInvalidTrunkVolume
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DataExportWorker собирает архивы выгрузки персональных данных из очереди
+// и удаляет архивы с истёкшим сроком хранения
+type DataExportWorker struct {
+	service AccountService
+	logger  *slog.Logger
+	tick    time.Duration
+}
+
+func NewDataExportWorker(service AccountService, logger *slog.Logger, tick time.Duration) *DataExportWorker {
+	return &DataExportWorker{
+		service: service,
+		logger:  logger,
+		tick:    tick,
+	}
+}
+
+func (w *DataExportWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("data export worker stopped")
+				return
+
+			case <-ticker.C:
+				count, err := w.service.ProcessExports(ctx, time.Now().UTC())
+				if err != nil {
+					w.logger.Error("failed to process data exports", slog.Any("error", err))
+					continue
+				}
+
+				if count > 0 {
+					w.logger.Info("data exports ready", slog.Int("count", count))
+				}
+			}
+		}
+	}()
+}
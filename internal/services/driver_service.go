@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+)
+
+var ErrNotTripDriver = errors.New("user is not the driver of this trip")
+
+const (
+	// телефоны пассажиров открываются водителю незадолго до отправления
+	phoneRevealWindow = 2 * time.Hour
+
+	// сколько завершённых поездок показывать в кабинете
+	dashboardPastLimit = 20
+
+	// сколько последних отзывов показывать в профиле водителя
+	profileReviewsLimit = 5
+)
+
+type DriverService interface {
+	Dashboard(driverID uint) (*dto.DriverDashboard, error)
+
+	Manifest(driverID, tripID uint) (*dto.TripManifest, error)
+
+	Profile(driverID uint) (*dto.DriverProfile, error)
+}
+
+type driverService struct {
+	tripRepo    repository.TripRepository
+	bookingRepo repository.BookingRepository
+	userRepo    repository.UserRepository
+	reviewRepo  repository.ReviewRepository
+	ratingRepo  repository.DriverRatingRepository
+	logger      *slog.Logger
+}
+
+func NewDriverService(
+	tripRepo repository.TripRepository,
+	bookingRepo repository.BookingRepository,
+	userRepo repository.UserRepository,
+	reviewRepo repository.ReviewRepository,
+	ratingRepo repository.DriverRatingRepository,
+	logger *slog.Logger,
+) DriverService {
+	return &driverService{
+		tripRepo:    tripRepo,
+		bookingRepo: bookingRepo,
+		userRepo:    userRepo,
+		reviewRepo:  reviewRepo,
+		ratingRepo:  ratingRepo,
+		logger:      logger,
+	}
+}
+
+func (s *driverService) Dashboard(driverID uint) (*dto.DriverDashboard, error) {
+	op := "service.driver.dashboard"
+
+	if _, err := s.userRepo.GetByID(driverID); err != nil {
+		return nil, err
+	}
+
+	upcoming, err := s.tripRepo.ListByDriver(driverID, string(constants.TripPublished), 0)
+	if err != nil {
+		s.logger.Error("error listing upcoming trips", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	inProgress, err := s.tripRepo.ListByDriver(driverID, string(constants.TripInProgress), 0)
+	if err != nil {
+		s.logger.Error("error listing trips in progress", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	past, err := s.tripRepo.ListByDriver(driverID, string(constants.TripCompleted), dashboardPastLimit)
+	if err != nil {
+		s.logger.Error("error listing past trips", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(upcoming)+len(inProgress)+len(past))
+	for _, group := range [][]models.Trip{upcoming, inProgress, past} {
+		for _, t := range group {
+			ids = append(ids, t.ID)
+		}
+	}
+
+	counts, err := s.bookingRepo.CountsByTrips(ids)
+	if err != nil {
+		s.logger.Error("error counting bookings", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &dto.DriverDashboard{
+		Upcoming:   summarizeTrips(upcoming, counts),
+		InProgress: summarizeTrips(inProgress, counts),
+		Past:       summarizeTrips(past, counts),
+	}, nil
+}
+
+func (s *driverService) Manifest(driverID, tripID uint) (*dto.TripManifest, error) {
+	op := "service.driver.manifest"
+
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.DriverID != driverID {
+		s.logger.Warn("manifest requested by foreign driver",
+			slog.String("op", op),
+			slog.Uint64("trip_id", uint64(tripID)),
+			slog.Uint64("driver_id", uint64(driverID)),
+		)
+		return nil, ErrNotTripDriver
+	}
+
+	rows, err := s.bookingRepo.ListManifest(tripID)
+	if err != nil {
+		s.logger.Error("error loading manifest", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	revealFrom := trip.StartTime.Add(-phoneRevealWindow)
+	reveal := !time.Now().Before(revealFrom)
+
+	manifest := &dto.TripManifest{
+		TripID:        trip.ID,
+		FromCity:      trip.FromCity,
+		ToCity:        trip.ToCity,
+		StartTime:     trip.StartTime,
+		TotalSeats:    trip.TotalSeats,
+		PhonesVisible: revealFrom,
+		Passengers:    make([]dto.ManifestPassenger, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		phone := row.Phone
+		if !reveal {
+			phone = maskPhone(phone)
+		}
+
+		manifest.BookedSeats += row.Seats
+		manifest.Passengers = append(manifest.Passengers, dto.ManifestPassenger{
+			BookingID:   row.BookingID,
+			PassengerID: row.PassengerID,
+			Name:        row.Name,
+			Phone:       phone,
+			PhoneMasked: !reveal,
+			Seats:       row.Seats,
+		})
+	}
+
+	return manifest, nil
+}
+
+// Profile собирает публичную карточку водителя. Пользователь без поездок и отзывов
+// получает пустой профиль с нулевым рейтингом; ошибка только если пользователя нет
+func (s *driverService) Profile(driverID uint) (*dto.DriverProfile, error) {
+	op := "service.driver.profile"
+
+	user, err := s.userRepo.GetByID(driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	rating, err := s.ratingRepo.GetByDriver(driverID)
+	if err != nil {
+		s.logger.Error("error loading driver rating", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	completed, err := s.tripRepo.CountByDriver(driverID, string(constants.TripCompleted))
+	if err != nil {
+		s.logger.Error("error counting completed trips", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	reviews, err := s.reviewRepo.ListByDriver(driverID, profileReviewsLimit)
+	if err != nil {
+		s.logger.Error("error listing driver reviews", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	profile := &dto.DriverProfile{
+		PublicProfile:  dto.NewPublicProfile(user, time.Now()),
+		CompletedTrips: completed,
+		Rating: dto.DriverRatingSummary{
+			Count:        rating.ReviewsCount,
+			Average:      rating.AvgRating,
+			Distribution: rating.Distribution(),
+			Categories:   rating.Categories(),
+		},
+		RecentReviews: make([]dto.DriverProfileReview, 0, len(reviews)),
+	}
+
+	for _, r := range reviews {
+		review := dto.DriverProfileReview{
+			ID:         r.ID,
+			TripID:     r.TripID,
+			AuthorName: r.AuthorName,
+			Rating:     r.Rating,
+			Text:       r.Text,
+			CreatedAt:  r.CreatedAt,
+		}
+
+		if r.ReplyText != nil && r.ReplyCreatedAt != nil {
+			review.Reply = &dto.DriverProfileReply{Text: *r.ReplyText, CreatedAt: *r.ReplyCreatedAt}
+		}
+
+		profile.RecentReviews = append(profile.RecentReviews, review)
+	}
+
+	return profile, nil
+}
+
+func summarizeTrips(trips []models.Trip, counts map[uint]models.BookingCounts) []dto.DriverTripSummary {
+	result := make([]dto.DriverTripSummary, 0, len(trips))
+
+	for _, t := range trips {
+		c := counts[t.ID]
+		result = append(result, dto.DriverTripSummary{
+			Trip:             t,
+			PendingBookings:  c.Pending,
+			ApprovedBookings: c.Approved,
+			ApprovedSeats:    c.ApprovedSeats,
+			SeatsLeft:        t.AvailableSeats,
+		})
+	}
+
+	return result
+}
+
+// maskPhone оставляет видимыми код страны и последние две цифры: +7*******67
+func maskPhone(phone string) string {
+	runes := []rune(phone)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+
+	visiblePrefix := 2
+	visibleSuffix := 2
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i < visiblePrefix || i >= len(runes)-visibleSuffix {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteRune('*')
+	}
+
+	return b.String()
+}
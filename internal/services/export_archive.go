@@ -0,0 +1,58 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+)
+
+// buildExportArchive упаковывает данные пользователя в ZIP: полная выгрузка в data.json
+// и по файлу на каждый раздел, чтобы их было удобно открыть по отдельности
+func buildExportArchive(data *dto.PersonalData) ([]byte, error) {
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"data.json", data},
+		{"profile.json", data.User},
+		{"cars.json", data.Cars},
+		{"car_photos.json", data.CarPhotos},
+		{"trips_as_driver.json", data.TripsAsDriver},
+		{"bookings.json", data.Bookings},
+		{"reviews_written.json", data.ReviewsWritten},
+		{"reviews_received.json", data.ReviewsReceived},
+		{"review_replies.json", data.ReviewReplies},
+		{"review_reports.json", data.ReviewReports},
+		{"helpful_votes.json", data.HelpfulVotes},
+		{"saved_searches.json", data.SavedSearches},
+		{"search_alerts.json", data.SearchAlerts},
+		{"blocks.json", data.Blocks},
+		{"verification_documents.json", data.VerificationDocuments},
+		{"sessions.json", data.Sessions},
+		{"notifications.json", data.Notifications},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
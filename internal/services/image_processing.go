@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrImageTooLarge       = errors.New("image file is too large")
+	ErrUnsupportedImage    = errors.New("unsupported image type")
+	ErrInvalidImageSize    = errors.New("image dimensions are out of range")
+	ErrImageDecodingFailed = errors.New("failed to decode image")
+)
+
+const (
+	maxImageBytes     = 10 << 20
+	minImageSide      = 100
+	maxImageSide      = 8000
+	maxStoredSide     = 1600
+	thumbnailSide     = 320
+	storedJPEGQuality = 85
+	thumbJPEGQuality  = 80
+)
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// processedImage — результат перекодирования загруженного файла
+type processedImage struct {
+	Full   []byte
+	Thumb  []byte
+	Width  int
+	Height int
+}
+
+// processImage проверяет загруженный файл и перекодирует его в JPEG двух размеров.
+// Перекодирование заодно вычищает EXIF (в том числе геометки) из исходника.
+func processImage(r io.Reader) (*processedImage, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, maxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) > maxImageBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	// тип определяем по содержимому, а не по имени файла или заголовку клиента
+	if !allowedImageTypes[http.DetectContentType(raw)] {
+		return nil, ErrUnsupportedImage
+	}
+
+	// размеры проверяем до полного декодирования, чтобы не распаковывать "бомбы"
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, ErrImageDecodingFailed
+	}
+
+	if cfg.Width < minImageSide || cfg.Height < minImageSide ||
+		cfg.Width > maxImageSide || cfg.Height > maxImageSide {
+		return nil, ErrInvalidImageSize
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, ErrImageDecodingFailed
+	}
+
+	full := resizeToFit(src, maxStoredSide)
+	thumb := resizeToFit(src, thumbnailSide)
+
+	fullBytes, err := encodeJPEG(full, storedJPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbBytes, err := encodeJPEG(thumb, thumbJPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processedImage{
+		Full:   fullBytes,
+		Thumb:  thumbBytes,
+		Width:  full.Bounds().Dx(),
+		Height: full.Bounds().Dy(),
+	}, nil
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resizeToFit уменьшает изображение так, чтобы большая сторона не превышала maxSide.
+// Используется усреднение по площади — для уменьшения этого достаточно и не нужен x/image.
+// Прозрачные пиксели накладываются на белый фон, так как JPEG не хранит альфа-канал.
+func resizeToFit(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	flat := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	if w <= maxSide && h <= maxSide {
+		return flat
+	}
+
+	dw, dh := maxSide, maxSide
+	if w > h {
+		dh = h * maxSide / w
+	} else {
+		dw = w * maxSide / h
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0 := y * h / dh
+		y1 := max((y+1)*h/dh, y0+1)
+
+		for x := 0; x < dw; x++ {
+			x0 := x * w / dw
+			x1 := max((x+1)*w/dw, x0+1)
+
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := flat.Pix[sy*flat.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4:]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					bl += uint32(p[2])
+					n++
+				}
+			}
+
+			o := dst.PixOffset(x, y)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(bl / n)
+			dst.Pix[o+3] = 0xff
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeGIF(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewPaletted(image.Rect(0, 0, w, h), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      []byte
+		wantErr    error
+		wantWidth  int
+		wantHeight int
+	}{
+		{"over size limit", make([]byte, maxImageBytes+1), ErrImageTooLarge, 0, 0},
+		{"plain text", []byte("definitely not an image"), ErrUnsupportedImage, 0, 0},
+		{"png signature with broken body", []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("x", 64)), ErrImageDecodingFailed, 0, 0},
+		{"narrower than minimum", encodePNG(t, minImageSide-1, 200), ErrInvalidImageSize, 0, 0},
+		{"shorter than minimum", encodePNG(t, 200, minImageSide-1), ErrInvalidImageSize, 0, 0},
+		{"wider than maximum", encodePNG(t, maxImageSide+1, minImageSide), ErrInvalidImageSize, 0, 0},
+		{"minimum size kept as is", encodePNG(t, minImageSide, minImageSide), nil, minImageSide, minImageSide},
+		{"landscape scaled to stored side", encodePNG(t, 2000, 1000), nil, maxStoredSide, maxStoredSide / 2},
+		{"portrait scaled to stored side", encodePNG(t, 1000, 2000), nil, maxStoredSide / 2, maxStoredSide},
+		{"gif accepted", encodeGIF(t, 150, 120), nil, 150, 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processImage(bytes.NewReader(tt.input))
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("size = %dx%d, want %dx%d", got.Width, got.Height, tt.wantWidth, tt.wantHeight)
+			}
+
+			thumb, err := jpegSize(got.Thumb)
+			if err != nil {
+				t.Fatalf("thumbnail is not a JPEG: %v", err)
+			}
+			if thumb.X > thumbnailSide || thumb.Y > thumbnailSide {
+				t.Errorf("thumbnail = %dx%d, larger than %d", thumb.X, thumb.Y, thumbnailSide)
+			}
+
+			if _, err := jpegSize(got.Full); err != nil {
+				t.Fatalf("full image is not a JPEG: %v", err)
+			}
+		})
+	}
+}
+
+func jpegSize(data []byte) (image.Point, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Point{}, err
+	}
+	if format != "jpeg" {
+		return image.Point{}, errors.New("format " + format)
+	}
+	return image.Point{X: cfg.Width, Y: cfg.Height}, nil
+}
+
+func TestResizeToFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSide       int
+		wantW, wantH  int
+	}{
+		{"fits already", 100, 50, 200, 100, 50},
+		{"exactly max side", 200, 200, 200, 200, 200},
+		{"landscape", 400, 100, 200, 200, 50},
+		{"portrait", 100, 400, 200, 50, 200},
+		{"square", 500, 500, 100, 100, 100},
+		{"extreme aspect keeps one pixel", 10000, 1, 100, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+
+			got := resizeToFit(src, tt.maxSide).Bounds()
+
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("size = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestResizeToFitPixels(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     func() image.Image
+		maxSide int
+		want    color.RGBA
+	}{
+		{
+			name: "transparent becomes white",
+			src: func() image.Image {
+				return image.NewNRGBA(image.Rect(0, 0, 2, 2))
+			},
+			maxSide: 2,
+			want:    color.RGBA{0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			name: "downscale averages the area",
+			src: func() image.Image {
+				img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+				img.Set(0, 0, color.Black)
+				img.Set(1, 0, color.White)
+				return img
+			},
+			maxSide: 1,
+			want:    color.RGBA{0x7f, 0x7f, 0x7f, 0xff},
+		},
+		{
+			name: "offset bounds are read from their origin",
+			src: func() image.Image {
+				img := image.NewRGBA(image.Rect(10, 10, 12, 12))
+				for y := 10; y < 12; y++ {
+					for x := 10; x < 12; x++ {
+						img.Set(x, y, color.RGBA{0x10, 0x20, 0x30, 0xff})
+					}
+				}
+				return img
+			},
+			maxSide: 1,
+			want:    color.RGBA{0x10, 0x20, 0x30, 0xff},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resizeToFit(tt.src(), tt.maxSide).RGBAAt(0, 0)
+
+			if got != tt.want {
+				t.Errorf("pixel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
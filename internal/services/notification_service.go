@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/notify"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// notificationLease — на сколько откладывается взятое в работу уведомление;
+	// если воркер упал посреди отправки, оно уйдёт повторно после этого срока
+	notificationLease = 5 * time.Minute
+
+	notificationBatchSize = 50
+)
+
+type NotificationService interface {
+	// Enqueue пишет уведомление в outbox в транзакции tx: оно уйдёт, только если транзакция
+	// закоммитится. Удалённым пользователям уведомления не пишутся
+	Enqueue(tx *gorm.DB, userID uint, kind constants.NotificationKind, data notify.Data) error
+
+	// Dispatch отправляет уведомления, которым пора уйти; вызывается воркером
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+}
+
+type notificationService struct {
+	repo     repository.NotificationRepository
+	userRepo repository.UserRepository
+	channels notify.Channels
+	retry    notify.Retry
+	logger   *slog.Logger
+}
+
+func NewNotificationService(
+	repo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	channels notify.Channels,
+	retry notify.Retry,
+	logger *slog.Logger,
+) NotificationService {
+	return &notificationService{
+		repo:     repo,
+		userRepo: userRepo,
+		channels: channels,
+		retry:    retry,
+		logger:   logger,
+	}
+}
+
+func (s *notificationService) Enqueue(tx *gorm.DB, userID uint, kind constants.NotificationKind, data notify.Data) error {
+	op := "service.notification.enqueue"
+
+	user, err := s.userRepo.WithDB(tx).GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.logger.Debug("notification recipient not found",
+				slog.String("op", op),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			return nil
+		}
+		return err
+	}
+
+	locale := notify.Locale(user.Languages)
+
+	subject, body, err := notify.Render(kind, locale, data)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	var notifications []models.Notification
+
+	for _, delivery := range notify.Route(user, s.channels) {
+		notifications = append(notifications, models.Notification{
+			UserID:        user.ID,
+			Kind:          kind,
+			Channel:       delivery.Channel,
+			Locale:        locale,
+			Recipient:     delivery.Recipient,
+			Subject:       subject,
+			Body:          body,
+			Status:        constants.NotificationPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	return s.repo.WithDB(tx).Create(notifications)
+}
+
+func (s *notificationService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	op := "service.notification.dispatch"
+
+	notifications, err := s.repo.ClaimDue(now, notificationLease, notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+
+	for _, n := range notifications {
+		attempts := n.Attempts + 1
+
+		err := s.channels.Send(ctx, notify.Message{
+			Channel:   n.Channel,
+			Recipient: n.Recipient,
+			Subject:   n.Subject,
+			Body:      n.Body,
+		})
+
+		if err == nil {
+			if err := s.repo.MarkSent(n.ID, attempts, now); err != nil {
+				return sent, err
+			}
+			sent++
+			continue
+		}
+
+		s.logger.Warn("notification delivery failed",
+			slog.String("op", op),
+			slog.Uint64("notification_id", uint64(n.ID)),
+			slog.String("channel", string(n.Channel)),
+			slog.Int("attempts", attempts),
+			slog.Any("error", err),
+		)
+
+		// выключенный канал не заработает от повторов
+		if attempts >= s.retry.MaxAttempts || errors.Is(err, notify.ErrChannelDisabled) {
+			err = s.repo.MarkFailed(n.ID, attempts, err.Error())
+		} else {
+			err = s.repo.MarkRetry(n.ID, attempts, now.Add(s.retry.Backoff(attempts)), err.Error())
+		}
+
+		if err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
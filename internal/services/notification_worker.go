@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// NotificationWorker отправляет уведомления из outbox и повторяет неудачные попытки
+type NotificationWorker struct {
+	service NotificationService
+	logger  *slog.Logger
+	tick    time.Duration
+}
+
+func NewNotificationWorker(service NotificationService, logger *slog.Logger, tick time.Duration) *NotificationWorker {
+	return &NotificationWorker{
+		service: service,
+		logger:  logger,
+		tick:    tick,
+	}
+}
+
+func (w *NotificationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("notification worker stopped")
+				return
+
+			case <-ticker.C:
+				count, err := w.service.Dispatch(ctx, time.Now().UTC())
+				if err != nil {
+					w.logger.Error("failed to dispatch notifications", slog.Any("error", err))
+					continue
+				}
+
+				if count > 0 {
+					w.logger.Info("notifications sent", slog.Int("count", count))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/storage"
+)
+
+var (
+	ErrAvatarNotSet     = errors.New("user has no avatar")
+	ErrTooManyCarPhotos = errors.New("car photo limit reached")
+)
+
+const (
+	photoURLTTL     = 15 * time.Minute
+	maxPhotosPerCar = 10
+)
+
+type PhotoService interface {
+	UploadUserAvatar(ctx context.Context, userID uint, file io.Reader) (*dto.PhotoResponse, error)
+
+	GetUserAvatarURL(ctx context.Context, userID uint, thumb bool) (string, error)
+
+	UploadCarPhoto(ctx context.Context, carID uint, file io.Reader) (*dto.PhotoResponse, error)
+
+	ListCarPhotos(ctx context.Context, carID uint) ([]dto.PhotoResponse, error)
+
+	DeleteCarPhoto(ctx context.Context, carID, photoID uint) error
+}
+
+type photoService struct {
+	photoRepo repository.PhotoRepository
+	userRepo  repository.UserRepository
+	carRepo   repository.CarRepository
+	blobs     storage.BlobStorage
+	logger    *slog.Logger
+}
+
+func NewPhotoService(
+	photoRepo repository.PhotoRepository,
+	userRepo repository.UserRepository,
+	carRepo repository.CarRepository,
+	blobs storage.BlobStorage,
+	logger *slog.Logger,
+) PhotoService {
+	return &photoService{
+		photoRepo: photoRepo,
+		userRepo:  userRepo,
+		carRepo:   carRepo,
+		blobs:     blobs,
+		logger:    logger,
+	}
+}
+
+func (s *photoService) UploadUserAvatar(ctx context.Context, userID uint, file io.Reader) (*dto.PhotoResponse, error) {
+	op := "service.photo.upload_user_avatar"
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := processImage(file)
+	if err != nil {
+		s.logger.Warn("rejected avatar upload", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	key, thumbKey, err := s.store(ctx, fmt.Sprintf("users/%d/avatar", userID), img)
+	if err != nil {
+		s.logger.Error("error storing avatar", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	oldKey, oldThumbKey := user.AvatarKey, user.AvatarThumbKey
+	user.AvatarKey = key
+	user.AvatarThumbKey = thumbKey
+
+	if err := s.userRepo.Update(userID, user); err != nil {
+		s.removeBlobs(ctx, key, thumbKey)
+		return nil, err
+	}
+
+	s.removeBlobs(ctx, oldKey, oldThumbKey)
+
+	s.logger.Info("avatar uploaded", slog.String("op", op), slog.Uint64("user_id", uint64(userID)))
+	return s.response(ctx, 0, key, thumbKey, img.Width, img.Height)
+}
+
+func (s *photoService) GetUserAvatarURL(ctx context.Context, userID uint, thumb bool) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	key := user.AvatarKey
+	if thumb {
+		key = user.AvatarThumbKey
+	}
+
+	if key == "" {
+		return "", ErrAvatarNotSet
+	}
+
+	return s.blobs.SignedURL(ctx, key, photoURLTTL)
+}
+
+func (s *photoService) UploadCarPhoto(ctx context.Context, carID uint, file io.Reader) (*dto.PhotoResponse, error) {
+	op := "service.photo.upload_car_photo"
+
+	if _, err := s.carRepo.GetByID(carID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.photoRepo.CountCarPhotos(carID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPhotosPerCar {
+		return nil, ErrTooManyCarPhotos
+	}
+
+	img, err := processImage(file)
+	if err != nil {
+		s.logger.Warn("rejected car photo upload", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	key, thumbKey, err := s.store(ctx, fmt.Sprintf("cars/%d/photos", carID), img)
+	if err != nil {
+		s.logger.Error("error storing car photo", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	photo := &models.CarPhoto{
+		CarID:       carID,
+		Key:         key,
+		ThumbKey:    thumbKey,
+		ContentType: "image/jpeg",
+		Width:       img.Width,
+		Height:      img.Height,
+	}
+
+	if err := s.photoRepo.CreateCarPhoto(photo); err != nil {
+		s.removeBlobs(ctx, key, thumbKey)
+		return nil, err
+	}
+
+	s.logger.Info("car photo uploaded", slog.String("op", op), slog.Uint64("car_id", uint64(carID)))
+	return s.response(ctx, photo.ID, key, thumbKey, photo.Width, photo.Height)
+}
+
+func (s *photoService) ListCarPhotos(ctx context.Context, carID uint) ([]dto.PhotoResponse, error) {
+	photos, err := s.photoRepo.ListCarPhotos(carID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.PhotoResponse, 0, len(photos))
+	for _, p := range photos {
+		resp, err := s.response(ctx, p.ID, p.Key, p.ThumbKey, p.Width, p.Height)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *resp)
+	}
+
+	return result, nil
+}
+
+func (s *photoService) DeleteCarPhoto(ctx context.Context, carID, photoID uint) error {
+	photo, err := s.photoRepo.GetCarPhoto(carID, photoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.photoRepo.DeleteCarPhoto(photo.ID); err != nil {
+		return err
+	}
+
+	s.removeBlobs(ctx, photo.Key, photo.ThumbKey)
+	return nil
+}
+
+func (s *photoService) store(ctx context.Context, prefix string, img *processedImage) (string, string, error) {
+	key, err := storage.NewKey(prefix, ".jpg")
+	if err != nil {
+		return "", "", err
+	}
+
+	thumbKey, err := storage.NewKey(prefix+"/thumbs", ".jpg")
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(img.Full), int64(len(img.Full)), "image/jpeg"); err != nil {
+		return "", "", err
+	}
+
+	if err := s.blobs.Put(ctx, thumbKey, bytes.NewReader(img.Thumb), int64(len(img.Thumb)), "image/jpeg"); err != nil {
+		s.removeBlobs(ctx, key)
+		return "", "", err
+	}
+
+	return key, thumbKey, nil
+}
+
+// removeBlobs удаляет объекты без возврата ошибки: осиротевший файл не должен ломать запрос
+func (s *photoService) removeBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			s.logger.Warn("failed to delete blob", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
+
+func (s *photoService) response(ctx context.Context, id uint, key, thumbKey string, width, height int) (*dto.PhotoResponse, error) {
+	url, err := s.blobs.SignedURL(ctx, key, photoURLTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbURL, err := s.blobs.SignedURL(ctx, thumbKey, photoURLTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PhotoResponse{
+		ID:        id,
+		URL:       url,
+		ThumbURL:  thumbURL,
+		Width:     width,
+		Height:    height,
+		ExpiresAt: time.Now().Add(photoURLTTL).UTC(),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+)
+
+// Правила возврата стоимости поездки пассажиру
+const (
+	fullRefundBefore    = 24 * time.Hour // отмена заранее — возврат полностью
+	partialRefundBefore = 2 * time.Hour  // отмена незадолго — половина
+	partialRefundPct    = 50
+)
+
+// refundPercent возвращает долю (в процентах), которую получит пассажир,
+// если заявка окажется в статусе status в момент now.
+func refundPercent(status constants.BookingStatus, startTime, now time.Time) int {
+	switch status {
+	case constants.BookingRejected, constants.BookingPending:
+		// водитель не взял пассажира — деньги возвращаются полностью
+		return 100
+	case constants.BookingNoShow:
+		return 0
+	case constants.BookingCancelled, constants.BookingApproved:
+		left := startTime.Sub(now)
+		switch {
+		case left >= fullRefundBefore:
+			return 100
+		case left >= partialRefundBefore:
+			return partialRefundPct
+		default:
+			return 0
+		}
+	}
+
+	return 0
+}
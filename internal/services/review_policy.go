@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+// Причины, по которым участник поездки не может оставить отзыв или получить его
+var (
+	ErrBookingNotApproved    = errors.New("only passengers with an approved booking can review this trip")
+	ErrPassengerNoShow       = errors.New("passenger did not show up for this trip")
+	ErrPassengerNotCheckedIn = errors.New("passenger was not checked in for this trip")
+	ErrReviewWindowClosed    = errors.New("review window for this trip has closed")
+
+	ErrRatingRequired       = errors.New("rating or at least one sub-rating is required")
+	ErrSubRatingsNotAllowed = errors.New("sub-ratings are only accepted in reviews of drivers")
+)
+
+const (
+	DefaultReviewWindow    = 14 * 24 * time.Hour
+	DefaultReplyEditWindow = 48 * time.Hour
+)
+
+// ReviewPolicy — правила приёма отзывов
+type ReviewPolicy struct {
+	// Window — сколько после окончания поездки принимаются отзывы.
+	// К концу окна вскрываются и запечатанные отзывы: встречного уже не будет.
+	Window time.Duration
+
+	// ReplyEditWindow — сколько после публикации водитель может править ответ на отзыв
+	ReplyEditWindow time.Duration
+
+	// Overall — как считать общую оценку, если автор поставил только оценки по категориям
+	Overall OverallRule
+}
+
+// OverallMode — способ свести оценки по категориям к одной
+type OverallMode string
+
+const (
+	OverallMean     OverallMode = "mean"     // среднее заполненных категорий
+	OverallMin      OverallMode = "min"      // худшая категория
+	OverallWeighted OverallMode = "weighted" // взвешенное среднее по Weights
+)
+
+type OverallRule struct {
+	Mode OverallMode
+
+	// Weights — веса категорий для OverallWeighted; категория без веса считается с весом 1
+	Weights map[constants.ReviewCategory]float64
+}
+
+// Derive выводит общую оценку 1–5; false, если ни одной категории не заполнено
+func (r OverallRule) Derive(sub models.SubRatings) (int, bool) {
+	values := sub.Values()
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	var overall float64
+
+	switch r.Mode {
+	case OverallMin:
+		overall = 5
+		for _, v := range values {
+			overall = math.Min(overall, float64(v))
+		}
+	default:
+		var sum, weights float64
+		for category, v := range values {
+			w := 1.0
+			if r.Mode == OverallWeighted {
+				if cw, ok := r.Weights[category]; ok {
+					w = cw
+				}
+			}
+			sum += w * float64(v)
+			weights += w
+		}
+		if weights <= 0 {
+			return 0, false
+		}
+		overall = sum / weights
+	}
+
+	return min(max(int(math.Round(overall)), 1), 5), true
+}
+
+func (p ReviewPolicy) Deadline(finishedAt time.Time) time.Time {
+	return finishedAt.Add(p.Window)
+}
+
+func (p ReviewPolicy) WindowOpen(finishedAt, now time.Time) bool {
+	return now.Before(p.Deadline(finishedAt))
+}
+
+func (p ReviewPolicy) ReplyEditable(createdAt, now time.Time) bool {
+	return now.Before(createdAt.Add(p.ReplyEditWindow))
+}
+
+// bookingReviewError проверяет заявку пассажира: отзыв возможен только по одобренной заявке,
+// а если водитель отмечал посадку — только для отмеченных пассажиров
+func bookingReviewError(booking *models.Booking, tripHasCheckIns bool) error {
+	switch booking.BookingStatus {
+	case constants.BookingApproved:
+	case constants.BookingNoShow:
+		return ErrPassengerNoShow
+	default:
+		return ErrBookingNotApproved
+	}
+
+	if tripHasCheckIns && booking.CheckedInAt == nil {
+		return ErrPassengerNotCheckedIn
+	}
+
+	return nil
+}
+
+// finishedAt — фактическое окончание поездки, а если его нет — плановое
+func finishedAt(actual *time.Time, start time.Time, durationMin int) time.Time {
+	if actual != nil {
+		return *actual
+	}
+	return start.Add(time.Duration(durationMin) * time.Minute)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ReviewRevealWorker вскрывает запечатанные отзывы, на которые вторая сторона
+// так и не ответила до конца срока двойного слепого рецензирования
+type ReviewRevealWorker struct {
+	service ReviewService
+	logger  *slog.Logger
+	tick    time.Duration
+}
+
+func NewReviewRevealWorker(service ReviewService, logger *slog.Logger, tick time.Duration) *ReviewRevealWorker {
+	return &ReviewRevealWorker{
+		service: service,
+		logger:  logger,
+		tick:    tick,
+	}
+}
+
+func (w *ReviewRevealWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.tick)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("review reveal worker stopped")
+				return
+
+			case <-ticker.C:
+				count, err := w.service.RevealDue(time.Now().UTC())
+				if err != nil {
+					w.logger.Error("failed to reveal reviews", slog.Any("error", err))
+					continue
+				}
+
+				if count > 0 {
+					w.logger.Info("sealed reviews revealed", slog.Int("count", count))
+				}
+			}
+		}
+	}()
+}
@@ -3,10 +3,13 @@ package services
 import (
 	"errors"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/moderation"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"gorm.io/gorm"
 )
@@ -15,39 +18,184 @@ var (
 	ErrTripNotCompleted     = errors.New("trip not completed")
 	ErrUserNotPassenger     = errors.New("user is not a passenger in this trip")
 	ErrReviewAlreadyPresent = errors.New("review already exists for this user and trip")
+	ErrReviewTargetRequired = errors.New("target_id is required when a driver reviews a passenger")
+	ErrInvalidReviewTarget  = errors.New("passengers can only review the driver of the trip")
+
+	ErrReviewAlreadyReported  = errors.New("review already reported by this user")
+	ErrCannotReportOwnReview  = errors.New("cannot report your own review")
+	ErrInvalidModerationState = errors.New("review cannot be moderated from its current status")
+	ErrModerationReasonNeeded = errors.New("reason is required to hide a review")
+
+	ErrCannotVoteOwnReview = errors.New("cannot mark your own review as helpful")
+
+	ErrReplyNotAllowed       = errors.New("only the reviewed driver can reply to this review")
+	ErrReplyAlreadyExists    = errors.New("review already has a reply")
+	ErrReplyEditWindowClosed = errors.New("reply can no longer be edited")
+)
+
+// ModerationAction — решение модератора по отзыву
+type ModerationAction string
+
+const (
+	ModerationApprove ModerationAction = "approve" // pending_moderation → published
+	ModerationHide    ModerationAction = "hide"    // pending_moderation/published → hidden
+	ModerationRestore ModerationAction = "restore" // hidden → published
+)
+
+const (
+	// сколько отзывов вскрывает воркер за один проход
+	reviewRevealBatch = 100
+
+	// после стольких жалоб опубликованный отзыв снимается до решения модератора
+	reviewReportThreshold = 3
 )
 
 type ReviewService interface {
 	Create(tripID, authorID uint, req *dto.ReviewCreateRequest) (*models.Review, error)
 
-	List(filter models.Page) ([]models.Review, error)
+	List(filter dto.ReviewFilter) ([]models.Review, models.PageInfo, error)
+
+	// Summary — число, средняя оценка и распределение по звёздам для выборки
+	Summary(filter dto.ReviewFilter) (*dto.ReviewSummary, error)
+
+	// SetHelpful ставит или снимает отметку «полезно», возвращает новое число отметок
+	SetHelpful(reviewID, voterID uint, helpful bool) (int, error)
 
-	GetByID(id uint) (*models.Review, error)
+	// GetByID отдаёт опубликованный отзыв; автору (viewerID) — и свой запечатанный
+	// или ждущий модерации
+	GetByID(id uint, viewerID *uint) (*models.Review, error)
 
 	Update(id, authorID uint, req *dto.ReviewUpdateRequest) (*models.Review, error)
 
 	Delete(id, authorID uint) error
+
+	// DeleteAsAdmin удаляет любой отзыв; рейтинги пересчитываются так же, как при удалении автором
+	DeleteAsAdmin(id uint) error
+
+	// RevealDue вскрывает запечатанные отзывы, у которых истёк срок ожидания встречного
+	RevealDue(now time.Time) (int, error)
+
+	Report(reviewID, reporterID uint, reason string) (*models.ReviewReport, error)
+
+	ListForModeration(status constants.ReviewStatus, filter models.Page) ([]models.Review, models.PageInfo, error)
+
+	ListReports(reviewID uint) ([]models.ReviewReport, error)
+
+	Moderate(id uint, action ModerationAction, moderatorID uint, reason string) (*models.Review, error)
+
+	// Reply — ответ водителя на отзыв о нём
+	Reply(reviewID, authorID uint, text string) (*models.ReviewReply, error)
+
+	// UpdateReply правит ответ, пока не истёк ReviewPolicy.ReplyEditWindow
+	UpdateReply(reviewID, authorID uint, text string) (*models.ReviewReply, error)
+
+	ListRepliesForModeration(status constants.ReviewStatus, filter models.Page) ([]models.ReviewReply, models.PageInfo, error)
+
+	ModerateReply(id uint, action ModerationAction, moderatorID uint, reason string) (*models.ReviewReply, error)
 }
 
 type reviewService struct {
-	reviewRepo repository.ReviewRepository
-	tripRepo   repository.TripRepository
-	logger     *slog.Logger
-	db         *gorm.DB
+	reviewRepo          repository.ReviewRepository
+	replyRepo           repository.ReviewReplyRepository
+	tripRepo            repository.TripRepository
+	bookingRepo         repository.BookingRepository
+	ratingRepo          repository.DriverRatingRepository
+	passengerRatingRepo repository.PassengerRatingRepository
+	policy              ReviewPolicy
+	filter              *moderation.Filter
+	logger              *slog.Logger
+	db                  *gorm.DB
 }
 
 func NewReviewService(
 	reviewRepo repository.ReviewRepository,
+	replyRepo repository.ReviewReplyRepository,
 	tripRepo repository.TripRepository,
+	bookingRepo repository.BookingRepository,
+	ratingRepo repository.DriverRatingRepository,
+	passengerRatingRepo repository.PassengerRatingRepository,
+	policy ReviewPolicy,
+	filter *moderation.Filter,
 	db *gorm.DB,
 	logger *slog.Logger,
 ) ReviewService {
 	return &reviewService{
-		reviewRepo: reviewRepo,
-		tripRepo:   tripRepo,
-		logger:     logger,
-		db:         db,
+		reviewRepo:          reviewRepo,
+		replyRepo:           replyRepo,
+		tripRepo:            tripRepo,
+		bookingRepo:         bookingRepo,
+		ratingRepo:          ratingRepo,
+		passengerRatingRepo: passengerRatingRepo,
+		policy:              policy,
+		filter:              filter,
+		logger:              logger,
+		db:                  db,
+	}
+}
+
+// reviewTx — репозитории, привязанные к одной транзакции
+type reviewTx struct {
+	reviews          repository.ReviewRepository
+	replies          repository.ReviewReplyRepository
+	trips            repository.TripRepository
+	bookings         repository.BookingRepository
+	driverRatings    repository.DriverRatingRepository
+	passengerRatings repository.PassengerRatingRepository
+}
+
+func (s *reviewService) withTx(tx *gorm.DB) reviewTx {
+	return reviewTx{
+		reviews:          s.reviewRepo.WithDB(tx),
+		replies:          s.replyRepo.WithDB(tx),
+		trips:            s.tripRepo.WithDB(tx),
+		bookings:         s.bookingRepo.WithDB(tx),
+		driverRatings:    s.ratingRepo.WithDB(tx),
+		passengerRatings: s.passengerRatingRepo.WithDB(tx),
+	}
+}
+
+// applyRating добавляет или убирает оценку учитываемого отзыва из рейтинга адресата
+func (t reviewTx) applyRating(review *models.Review, add bool) error {
+	if review.Direction == constants.ReviewDriverToPassenger {
+		if add {
+			return t.passengerRatings.AddReview(review.TargetID, review.Rating)
+		}
+		return t.passengerRatings.RemoveReview(review.TargetID, review.Rating)
+	}
+
+	if add {
+		return t.driverRatings.AddReview(review.TargetID, review.Rating, review.SubRatings, review.CreatedAt)
+	}
+	return t.driverRatings.RemoveReview(review.TargetID, review.Rating, review.SubRatings, review.CreatedAt)
+}
+
+// syncRating переносит изменения отзыва в рейтинги: убирает старую оценку, если она
+// учитывалась, и добавляет новую, если учитывается теперь
+func (t reviewTx) syncRating(before, after *models.Review) error {
+	if before.Counted() == after.Counted() && before.Rating == after.Rating &&
+		before.SubRatings.Equal(after.SubRatings) {
+		return nil
+	}
+
+	if before.Counted() {
+		if err := t.applyRating(before, false); err != nil {
+			return err
+		}
+	}
+
+	if after.Counted() {
+		return t.applyRating(after, true)
+	}
+	return nil
+}
+
+func (t reviewTx) refreshTripRating(tripID uint) error {
+	avgRating, err := t.reviews.GetAvgRatingByTrip(tripID)
+	if err != nil {
+		return err
 	}
+
+	return t.trips.UpdateAvgRating(tripID, avgRating)
 }
 
 func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateRequest) (*models.Review, error) {
@@ -56,10 +204,9 @@ func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateReque
 	var created *models.Review
 
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		tr := s.tripRepo.WithDB(tx)
-		rr := s.reviewRepo.WithDB(tx)
+		t := s.withTx(tx)
 
-		trip, err := tr.GetByID(tripID)
+		trip, err := t.trips.GetByID(tripID)
 		if err != nil {
 			return err
 		}
@@ -72,21 +219,30 @@ func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateReque
 			return ErrTripNotCompleted
 		}
 
-		isPassenger, err := tr.IsPassenger(tripID, authorId)
-		if err != nil {
-			return err
+		now := time.Now()
+		finished := finishedAt(trip.FinishedAt, trip.StartTime, trip.DurationMin)
+
+		if !s.policy.WindowOpen(finished, now) {
+			return ErrReviewWindowClosed
 		}
 
-		if !isPassenger {
-			s.logger.Error("user is not passenger",
+		direction, targetID, err := s.reviewTarget(t, trip, authorId, req.TargetID)
+		if err != nil {
+			s.logger.Error("review target rejected",
 				slog.String("op", op),
 				slog.Uint64("userID", uint64(authorId)),
 				slog.Uint64("tripID", uint64(tripID)),
+				slog.Any("error", err),
 			)
-			return ErrUserNotPassenger
+			return err
+		}
+
+		if err := t.reviews.LockPair(tripID, authorId, targetID); err != nil {
+			s.logger.Error("error locking review pair", slog.String("op", op), slog.Any("error", err))
+			return err
 		}
 
-		exists, err := rr.ExistsByTripAndUser(tripID, authorId)
+		exists, err := t.reviews.ExistsByTripAndUser(tripID, authorId, targetID)
 		if err != nil {
 			s.logger.Error("error checking review existence", slog.String("op", op), slog.Any("error", err))
 			return err
@@ -97,25 +253,60 @@ func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateReque
 			return ErrReviewAlreadyPresent
 		}
 
+		counterpart, err := t.reviews.GetCounterpart(tripID, authorId, targetID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		revealAt := s.policy.Deadline(finished)
+
 		review := &models.Review{
-			AuthorID: authorId,
-			TripID:   tripID,
-			Rating:   req.Rating,
-			Text:     req.Text,
+			AuthorID:   authorId,
+			TripID:     tripID,
+			TargetID:   targetID,
+			Direction:  direction,
+			Rating:     req.Rating,
+			SubRatings: req.SubRatings.Apply(models.SubRatings{}),
+			Text:       req.Text,
+			// встречный уже есть — скрывать больше нечего
+			Sealed:   counterpart == nil,
+			RevealAt: &revealAt,
+			Status:   constants.ReviewPublished,
 		}
 
-		if err := rr.Create(review); err != nil {
-			s.logger.Error("error creating review", slog.String("op", op), slog.Any("error", err))
+		if err := s.resolveRating(review); err != nil {
 			return err
 		}
 
-		avgRating, err := rr.GetAvgRatingByTrip(tripID)
-		if err != nil {
-			s.logger.Error("error calculating average rating", slog.String("op", op), slog.Any("error", err))
+		s.screen(review)
+
+		if err := t.reviews.Create(review); err != nil {
+			s.logger.Error("error creating review", slog.String("op", op), slog.Any("error", err))
 			return err
 		}
 
-		if err := tr.UpdateAvgRating(tripID, avgRating); err != nil {
+		if review.Counted() {
+			if err := t.applyRating(review, true); err != nil {
+				s.logger.Error("error updating user rating", slog.String("op", op), slog.Any("error", err))
+				return err
+			}
+		}
+
+		if counterpart != nil && counterpart.Sealed {
+			revealed, err := t.reviews.Reveal(counterpart.ID)
+			if err != nil {
+				return err
+			}
+			counterpart.Sealed = false
+			if revealed && counterpart.Counted() {
+				if err := t.applyRating(counterpart, true); err != nil {
+					s.logger.Error("error updating user rating", slog.String("op", op), slog.Any("error", err))
+					return err
+				}
+			}
+		}
+
+		if err := t.refreshTripRating(tripID); err != nil {
 			s.logger.Error("error updating trip average rating", slog.String("op", op), slog.Any("error", err))
 			return err
 		}
@@ -130,23 +321,206 @@ func (s *reviewService) Create(tripID, authorId uint, req *dto.ReviewCreateReque
 	return created, nil
 }
 
-func (s *reviewService) List(filter models.Page) ([]models.Review, error) {
+// resolveRating проверяет оценки по категориям и, если общая оценка не указана,
+// выводит её по правилу ReviewPolicy.Overall
+func (s *reviewService) resolveRating(review *models.Review) error {
+	if !review.SubRatings.IsEmpty() && review.Direction != constants.ReviewPassengerToDriver {
+		return ErrSubRatingsNotAllowed
+	}
+
+	if review.Rating != 0 && !review.RatingDerived {
+		return nil
+	}
+
+	overall, ok := s.policy.Overall.Derive(review.SubRatings)
+	if !ok {
+		return ErrRatingRequired
+	}
+
+	review.Rating = overall
+	review.RatingDerived = true
+	return nil
+}
+
+// screenText прогоняет текст через автофильтр и возвращает причины через запятую;
+// пустая строка — текст чистый
+func (s *reviewService) screenText(text string) string {
+	reasons := s.filter.Check(text)
+
+	flags := make([]string, len(reasons))
+	for i, r := range reasons {
+		flags[i] = string(r)
+	}
+
+	return strings.Join(flags, ",")
+}
+
+// screenStatus — подозрительный текст уходит модератору, скрытый остаётся скрытым
+func screenStatus(current constants.ReviewStatus, flags string) constants.ReviewStatus {
+	if flags != "" && current == constants.ReviewPublished {
+		return constants.ReviewPendingModeration
+	}
+	return current
+}
+
+// screen проверяет текст отзыва: отправленный модератору отзыв в рейтинги не попадает
+func (s *reviewService) screen(review *models.Review) {
+	flags := s.screenText(review.Text)
+	if flags == "" {
+		return
+	}
+
+	review.ModerationFlags = flags
+	review.Status = screenStatus(review.Status, flags)
+
+	s.logger.Info("review flagged for moderation",
+		slog.Uint64("author_id", uint64(review.AuthorID)),
+		slog.Uint64("trip_id", uint64(review.TripID)),
+		slog.String("flags", review.ModerationFlags),
+	)
+}
+
+// reviewTarget определяет направление отзыва: водитель оценивает указанного пассажира,
+// пассажир — водителя поездки
+func (s *reviewService) reviewTarget(t reviewTx, trip *models.Trip, authorID uint, targetID *uint) (constants.ReviewDirection, uint, error) {
+	if authorID == trip.DriverID {
+		if targetID == nil {
+			return "", 0, ErrReviewTargetRequired
+		}
+
+		if err := checkPassenger(t, trip.ID, *targetID); err != nil {
+			return "", 0, err
+		}
+
+		return constants.ReviewDriverToPassenger, *targetID, nil
+	}
+
+	if targetID != nil && *targetID != trip.DriverID {
+		return "", 0, ErrInvalidReviewTarget
+	}
+
+	if err := checkPassenger(t, trip.ID, authorID); err != nil {
+		return "", 0, err
+	}
+
+	return constants.ReviewPassengerToDriver, trip.DriverID, nil
+}
+
+// checkPassenger проверяет, что пассажир действительно ехал: оценивать и быть оценённым
+// может только он
+func checkPassenger(t reviewTx, tripID, passengerID uint) error {
+	booking, err := t.bookings.GetForReview(tripID, passengerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotPassenger
+		}
+		return err
+	}
+
+	hasCheckIns := false
+	if booking.BookingStatus == constants.BookingApproved && booking.CheckedInAt == nil {
+		if hasCheckIns, err = t.bookings.HasCheckIns(tripID); err != nil {
+			return err
+		}
+	}
+
+	return bookingReviewError(booking, hasCheckIns)
+}
+
+func (s *reviewService) List(filter dto.ReviewFilter) ([]models.Review, models.PageInfo, error) {
 
 	op := "service.review.list"
 
 	s.logger.Debug(" call", slog.String("op", op))
 
-	reviews, err := s.reviewRepo.List(filter)
+	reviews, info, err := s.reviewRepo.List(filter)
 	if err != nil {
 		s.logger.Error(" error", slog.String("op", op), slog.Any("error", err))
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 
 	s.logger.Info("reviews listed", slog.String("op", op), slog.Int("count", len(reviews)))
-	return reviews, nil
+	return reviews, info, nil
+}
+
+func (s *reviewService) Summary(filter dto.ReviewFilter) (*dto.ReviewSummary, error) {
+	op := "service.review.summary"
+
+	histogram, err := s.reviewRepo.RatingHistogram(filter)
+	if err != nil {
+		s.logger.Error("error building review summary", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	summary := &dto.ReviewSummary{Distribution: make(map[int]int, 5)}
+	sum := 0
+
+	for stars := 1; stars <= 5; stars++ {
+		n := histogram[stars]
+		summary.Distribution[stars] = n
+		summary.Count += n
+		sum += n * stars
+	}
+
+	if summary.Count > 0 {
+		summary.Average = float64(sum) / float64(summary.Count)
+	}
+
+	return summary, nil
+}
+
+func (s *reviewService) SetHelpful(reviewID, voterID uint, helpful bool) (int, error) {
+	op := "service.review.set_helpful"
+
+	var count int
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		t := s.withTx(tx)
+
+		review, err := t.reviews.GetByID(reviewID)
+		if err != nil {
+			return err
+		}
+
+		if !review.Counted() {
+			return repository.ErrNotFound
+		}
+
+		if review.AuthorID == voterID {
+			return ErrCannotVoteOwnReview
+		}
+
+		// повторная отметка или снятие несуществующей ничего не меняют
+		count = review.HelpfulCount
+		if helpful {
+			added, err := t.reviews.AddHelpfulVote(reviewID, voterID)
+			if err != nil {
+				return err
+			}
+			if added {
+				count++
+			}
+			return nil
+		}
+
+		removed, err := t.reviews.RemoveHelpfulVote(reviewID, voterID)
+		if err != nil {
+			return err
+		}
+		if removed && count > 0 {
+			count--
+		}
+		return nil
+	})
+
+	if err != nil {
+		s.logger.Error("error saving helpful vote", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+	return count, nil
 }
 
-func (s *reviewService) GetByID(id uint) (*models.Review, error) {
+func (s *reviewService) GetByID(id uint, viewerID *uint) (*models.Review, error) {
 	op := "service.review.getByID"
 	s.logger.Debug("call", slog.String("op", op), slog.Uint64("id", uint64(id)))
 
@@ -155,6 +529,13 @@ func (s *reviewService) GetByID(id uint) (*models.Review, error) {
 		s.logger.Error("error", slog.String("op", op), slog.Any("error", err))
 		return nil, err
 	}
+
+	// запечатанный или не прошедший модерацию отзыв не публичен, но автор свой видит
+	isAuthor := viewerID != nil && *viewerID == review.AuthorID
+	if !review.Counted() && !isAuthor {
+		return nil, repository.ErrNotFound
+	}
+
 	s.logger.Info("review retrieved", slog.String("op", op), slog.Uint64("id", uint64(id)))
 	return review, nil
 }
@@ -165,10 +546,9 @@ func (s *reviewService) Update(id, authorID uint, req *dto.ReviewUpdateRequest)
 	var updated *models.Review
 
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		rr := s.reviewRepo.WithDB(tx)
-		tr := s.tripRepo.WithDB(tx)
+		t := s.withTx(tx)
 
-		review, err := rr.GetByID(id)
+		review, err := t.reviews.GetByID(id)
 		if err != nil {
 			return err
 		}
@@ -177,24 +557,36 @@ func (s *reviewService) Update(id, authorID uint, req *dto.ReviewUpdateRequest)
 			return errors.New("permission denied")
 		}
 
+		before := *review
+
 		if req.Text != nil {
 			review.Text = *req.Text
+			// правка текста не снимает скрытие, но новый текст снова проверяется
+			s.screen(review)
 		}
 		if req.Rating != nil {
 			review.Rating = *req.Rating
+			review.RatingDerived = false
+		}
+		review.SubRatings = req.SubRatings.Apply(review.SubRatings)
+
+		// выведенная оценка пересчитывается вслед за категориями
+		if err := s.resolveRating(review); err != nil {
+			return err
 		}
 
-		if _, err := rr.Update(review); err != nil {
+		if _, err := t.reviews.Update(review); err != nil {
 			s.logger.Error("error updating review", slog.String("op", op), slog.Any("error", err))
 			return err
 		}
 
-		avgRating, err := rr.GetAvgRatingByTrip(review.TripID)
-		if err != nil {
+		if err := t.syncRating(&before, review); err != nil {
+			s.logger.Error("error updating user rating", slog.String("op", op), slog.Any("error", err))
 			return err
 		}
 
-		if err := tr.UpdateAvgRating(review.TripID, avgRating); err != nil {
+		if err := t.refreshTripRating(review.TripID); err != nil {
+			s.logger.Error("error updating trip average rating", slog.String("op", op), slog.Any("error", err))
 			return err
 		}
 
@@ -209,31 +601,425 @@ func (s *reviewService) Update(id, authorID uint, req *dto.ReviewUpdateRequest)
 }
 
 func (s *reviewService) Delete(id, authorID uint) error {
+	return s.delete(id, func(review *models.Review) error {
+		if review.AuthorID != authorID {
+			return errors.New("permission denied")
+		}
+		return nil
+	})
+}
+
+func (s *reviewService) DeleteAsAdmin(id uint) error {
+	return s.delete(id, func(*models.Review) error { return nil })
+}
+
+// delete удаляет отзыв, если allowed не возражает, и убирает его оценку из рейтингов
+func (s *reviewService) delete(id uint, allowed func(review *models.Review) error) error {
 	op := "service.review.delete"
 
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		rr := s.reviewRepo.WithDB(tx)
-		tr := s.tripRepo.WithDB(tx)
+		t := s.withTx(tx)
 
-		review, err := rr.GetByID(id)
+		review, err := t.reviews.GetByID(id)
 		if err != nil {
 			return err
 		}
 
-		if review.AuthorID != authorID {
-			return errors.New("permission denied")
+		if err := allowed(review); err != nil {
+			return err
 		}
 
-		if err := rr.Delete(id); err != nil {
+		if err := t.reviews.Delete(id); err != nil {
 			s.logger.Error("error deleting review", slog.String("op", op), slog.Any("error", err))
 			return err
 		}
 
-		avgRating, err := rr.GetAvgRatingByTrip(review.TripID)
+		if review.Counted() {
+			if err := t.applyRating(review, false); err != nil {
+				s.logger.Error("error updating user rating", slog.String("op", op), slog.Any("error", err))
+				return err
+			}
+		}
+
+		return t.refreshTripRating(review.TripID)
+	})
+}
+
+func (s *reviewService) RevealDue(now time.Time) (int, error) {
+	op := "service.review.reveal_due"
+
+	due, err := s.reviewRepo.ListDueForReveal(now, reviewRevealBatch)
+	if err != nil {
+		s.logger.Error("error listing sealed reviews", slog.String("op", op), slog.Any("error", err))
+		return 0, err
+	}
+
+	count := 0
+
+	for i := range due {
+		review := &due[i]
+		revealed := false
+
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			t := s.withTx(tx)
+
+			var err error
+			// отзыв мог вскрыться встречным между выборкой и транзакцией
+			if revealed, err = t.reviews.Reveal(review.ID); err != nil || !revealed {
+				return err
+			}
+
+			// отзыв на модерации вскрывается, но в рейтинг попадёт только после одобрения
+			review.Sealed = false
+			if !review.Counted() {
+				return nil
+			}
+
+			if err := t.applyRating(review, true); err != nil {
+				return err
+			}
+
+			return t.refreshTripRating(review.TripID)
+		})
+		if err != nil {
+			s.logger.Error("error revealing review",
+				slog.String("op", op),
+				slog.Uint64("review_id", uint64(review.ID)),
+				slog.Any("error", err),
+			)
+			return count, err
+		}
+
+		if revealed {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *reviewService) Report(reviewID, reporterID uint, reason string) (*models.ReviewReport, error) {
+	op := "service.review.report"
+
+	var report *models.ReviewReport
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		t := s.withTx(tx)
+
+		review, err := t.reviews.GetByID(reviewID)
+		if err != nil {
+			return err
+		}
+
+		// жаловаться можно только на то, что видно публично
+		if !review.Counted() {
+			return repository.ErrNotFound
+		}
+
+		if review.AuthorID == reporterID {
+			return ErrCannotReportOwnReview
+		}
+
+		report = &models.ReviewReport{
+			ReviewID:   reviewID,
+			ReporterID: reporterID,
+			Reason:     reason,
+		}
+
+		created, err := t.reviews.CreateReport(report)
 		if err != nil {
+			s.logger.Error("error creating review report", slog.String("op", op), slog.Any("error", err))
+			return err
+		}
+		if !created {
+			return ErrReviewAlreadyReported
+		}
+
+		count, err := t.reviews.CountReports(reviewID)
+		if err != nil {
+			return err
+		}
+		if count < reviewReportThreshold {
+			return nil
+		}
+
+		moved, err := t.reviews.SetStatus(reviewID, constants.ReviewPublished, constants.ReviewPendingModeration, "user reports", nil)
+		if err != nil || !moved {
 			return err
 		}
 
-		return tr.UpdateAvgRating(review.TripID, avgRating)
+		before := *review
+		review.Status = constants.ReviewPendingModeration
+
+		if err := t.syncRating(&before, review); err != nil {
+			s.logger.Error("error updating user rating", slog.String("op", op), slog.Any("error", err))
+			return err
+		}
+
+		s.logger.Info("review sent to moderation by reports",
+			slog.String("op", op),
+			slog.Uint64("review_id", uint64(reviewID)),
+			slog.Int64("reports", count),
+		)
+
+		return t.refreshTripRating(review.TripID)
 	})
+
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *reviewService) ListForModeration(status constants.ReviewStatus, filter models.Page) ([]models.Review, models.PageInfo, error) {
+	op := "service.review.list_for_moderation"
+
+	reviews, info, err := s.reviewRepo.ListForModeration(status, filter)
+	if err != nil {
+		s.logger.Error("error listing reviews for moderation", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	return reviews, info, nil
+}
+
+func (s *reviewService) ListReports(reviewID uint) ([]models.ReviewReport, error) {
+	op := "service.review.list_reports"
+
+	if _, err := s.reviewRepo.GetByID(reviewID); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reviewRepo.ListReports(reviewID)
+	if err != nil {
+		s.logger.Error("error listing review reports", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// moderationTarget — в какой статус переводит действие из текущего
+func moderationTarget(action ModerationAction, current constants.ReviewStatus) (constants.ReviewStatus, bool) {
+	switch action {
+	case ModerationApprove:
+		return constants.ReviewPublished, current == constants.ReviewPendingModeration
+	case ModerationHide:
+		return constants.ReviewHidden, current == constants.ReviewPendingModeration || current == constants.ReviewPublished
+	case ModerationRestore:
+		return constants.ReviewPublished, current == constants.ReviewHidden
+	}
+	return "", false
+}
+
+func (s *reviewService) Moderate(id uint, action ModerationAction, moderatorID uint, reason string) (*models.Review, error) {
+	op := "service.review.moderate"
+
+	if action == ModerationHide && strings.TrimSpace(reason) == "" {
+		return nil, ErrModerationReasonNeeded
+	}
+
+	var moderated *models.Review
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		t := s.withTx(tx)
+
+		review, err := t.reviews.GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		to, ok := moderationTarget(action, review.Status)
+		if !ok {
+			return ErrInvalidModerationState
+		}
+
+		// условие на текущий статус защищает от двух модераторов одновременно
+		changed, err := t.reviews.SetStatus(id, review.Status, to, reason, &moderatorID)
+		if err != nil {
+			s.logger.Error("error moderating review", slog.String("op", op), slog.Any("error", err))
+			return err
+		}
+		if !changed {
+			return ErrInvalidModerationState
+		}
+
+		before := *review
+		now := time.Now()
+		review.Status = to
+		review.ModerationReason = reason
+		review.ModeratedBy = &moderatorID
+		review.ModeratedAt = &now
+
+		if err := t.syncRating(&before, review); err != nil {
+			s.logger.Error("error updating user rating", slog.String("op", op), slog.Any("error", err))
+			return err
+		}
+
+		if err := t.refreshTripRating(review.TripID); err != nil {
+			s.logger.Error("error updating trip average rating", slog.String("op", op), slog.Any("error", err))
+			return err
+		}
+
+		moderated = review
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("review moderated",
+		slog.String("op", op),
+		slog.Uint64("review_id", uint64(id)),
+		slog.String("action", string(action)),
+		slog.Uint64("moderator_id", uint64(moderatorID)),
+	)
+	return moderated, nil
+}
+
+func (s *reviewService) Reply(reviewID, authorID uint, text string) (*models.ReviewReply, error) {
+	op := "service.review.reply"
+
+	var created *models.ReviewReply
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		t := s.withTx(tx)
+
+		review, err := t.reviews.GetByID(reviewID)
+		if err != nil {
+			return err
+		}
+
+		// отвечать можно только на опубликованный отзыв
+		if !review.Counted() {
+			return repository.ErrNotFound
+		}
+
+		if review.Direction != constants.ReviewPassengerToDriver || review.TargetID != authorID {
+			return ErrReplyNotAllowed
+		}
+
+		// review.Reply подгружается только опубликованным — проверяем и скрытые
+		if _, err := t.replies.GetByReview(reviewID); err == nil {
+			return ErrReplyAlreadyExists
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		flags := s.screenText(text)
+		reply := &models.ReviewReply{
+			ReviewID:        reviewID,
+			AuthorID:        authorID,
+			Text:            text,
+			Status:          screenStatus(constants.ReviewPublished, flags),
+			ModerationFlags: flags,
+		}
+
+		if err := t.replies.Create(reply); err != nil {
+			// параллельный запрос успел создать ответ между проверкой и вставкой
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrReplyAlreadyExists
+			}
+			s.logger.Error("error creating review reply", slog.String("op", op), slog.Any("error", err))
+			return err
+		}
+
+		created = reply
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("review reply created",
+		slog.String("op", op),
+		slog.Uint64("review_id", uint64(reviewID)),
+		slog.String("status", string(created.Status)),
+	)
+	return created, nil
+}
+
+func (s *reviewService) UpdateReply(reviewID, authorID uint, text string) (*models.ReviewReply, error) {
+	op := "service.review.update_reply"
+
+	reply, err := s.replyRepo.GetByReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.AuthorID != authorID {
+		return nil, ErrReplyNotAllowed
+	}
+
+	if !s.policy.ReplyEditable(reply.CreatedAt, time.Now()) {
+		return nil, ErrReplyEditWindowClosed
+	}
+
+	flags := s.screenText(text)
+
+	reply.Text = text
+	reply.ModerationFlags = flags
+	reply.Status = screenStatus(reply.Status, flags)
+
+	if err := s.replyRepo.UpdateText(reply); err != nil {
+		s.logger.Error("error updating review reply", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (s *reviewService) ListRepliesForModeration(status constants.ReviewStatus, filter models.Page) ([]models.ReviewReply, models.PageInfo, error) {
+	op := "service.review.list_replies_for_moderation"
+
+	replies, info, err := s.replyRepo.ListForModeration(status, filter)
+	if err != nil {
+		s.logger.Error("error listing replies for moderation", slog.String("op", op), slog.Any("error", err))
+		return nil, models.PageInfo{}, err
+	}
+
+	return replies, info, nil
+}
+
+func (s *reviewService) ModerateReply(id uint, action ModerationAction, moderatorID uint, reason string) (*models.ReviewReply, error) {
+	op := "service.review.moderate_reply"
+
+	if action == ModerationHide && strings.TrimSpace(reason) == "" {
+		return nil, ErrModerationReasonNeeded
+	}
+
+	reply, err := s.replyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	to, ok := moderationTarget(action, reply.Status)
+	if !ok {
+		return nil, ErrInvalidModerationState
+	}
+
+	changed, err := s.replyRepo.SetStatus(id, reply.Status, to, reason, moderatorID)
+	if err != nil {
+		s.logger.Error("error moderating review reply", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrInvalidModerationState
+	}
+
+	now := time.Now()
+	reply.Status = to
+	reply.ModerationReason = reason
+	reply.ModeratedBy = &moderatorID
+	reply.ModeratedAt = &now
+
+	s.logger.Info("review reply moderated",
+		slog.String("op", op),
+		slog.Uint64("reply_id", uint64(id)),
+		slog.String("action", string(action)),
+		slog.Uint64("moderator_id", uint64(moderatorID)),
+	)
+	return reply, nil
 }
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/notify"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidSearchWindow  = errors.New("date_from must be before date_to")
+	ErrTooManySavedSearches = errors.New("saved search limit reached")
+	ErrNotSearchOwner       = errors.New("saved search belongs to another user")
+)
+
+const (
+	maxSavedSearchesPerUser = 20
+
+	// не больше стольких уведомлений о новых поездках одному пользователю за сутки (UTC)
+	dailySearchAlertCap = 10
+)
+
+type SavedSearchService interface {
+	Create(userID uint, req *dto.SavedSearchCreateRequest) (*models.SavedSearch, error)
+
+	ListByUser(userID uint) ([]models.SavedSearch, error)
+
+	Delete(userID, id uint) error
+
+	SearchMatcher
+}
+
+// SearchMatcher вызывается после публикации поездки и ставит уведомления подписчикам в очередь
+type SearchMatcher interface {
+	MatchTrip(trip *models.Trip)
+}
+
+type savedSearchService struct {
+	searchRepo repository.SavedSearchRepository
+	userRepo   repository.UserRepository
+	notifier   NotificationService
+	db         *gorm.DB
+	logger     *slog.Logger
+}
+
+func NewSavedSearchService(
+	searchRepo repository.SavedSearchRepository,
+	userRepo repository.UserRepository,
+	notifier NotificationService,
+	db *gorm.DB,
+	logger *slog.Logger,
+) SavedSearchService {
+	return &savedSearchService{
+		searchRepo: searchRepo,
+		userRepo:   userRepo,
+		notifier:   notifier,
+		db:         db,
+		logger:     logger,
+	}
+}
+
+func (s *savedSearchService) Create(userID uint, req *dto.SavedSearchCreateRequest) (*models.SavedSearch, error) {
+	op := "service.saved_search.create"
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	if req.DateFrom != nil && req.DateTo != nil && req.DateTo.Before(*req.DateFrom) {
+		return nil, ErrInvalidSearchWindow
+	}
+
+	count, err := s.searchRepo.CountActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxSavedSearchesPerUser {
+		return nil, ErrTooManySavedSearches
+	}
+
+	seats := req.Seats
+	if seats == 0 {
+		seats = 1
+	}
+
+	search := &models.SavedSearch{
+		UserID:   userID,
+		FromCity: strings.TrimSpace(req.FromCity),
+		ToCity:   strings.TrimSpace(req.ToCity),
+		DateFrom: req.DateFrom,
+		DateTo:   req.DateTo,
+		Seats:    seats,
+		MaxPrice: req.MaxPrice,
+		Active:   true,
+	}
+
+	if err := s.searchRepo.Create(search); err != nil {
+		s.logger.Error("error saving search", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	s.logger.Info("search saved", slog.String("op", op), slog.Uint64("search_id", uint64(search.ID)))
+	return search, nil
+}
+
+func (s *savedSearchService) ListByUser(userID uint) ([]models.SavedSearch, error) {
+	return s.searchRepo.ListByUser(userID)
+}
+
+func (s *savedSearchService) Delete(userID, id uint) error {
+	search, err := s.searchRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if search.UserID != userID {
+		return ErrNotSearchOwner
+	}
+
+	return s.searchRepo.Delete(id)
+}
+
+// MatchTrip подбирает сохранённые поиски под новую поездку и ставит уведомления в outbox.
+// Ошибки только логируются: публикация поездки не должна падать из-за рассылки.
+func (s *savedSearchService) MatchTrip(trip *models.Trip) {
+	op := "service.saved_search.match_trip"
+
+	searches, err := s.searchRepo.FindMatching(trip)
+	if err != nil {
+		s.logger.Error("error matching saved searches", slog.String("op", op), slog.Any("error", err))
+		return
+	}
+
+	now := time.Now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	notified := make(map[uint]bool, len(searches))
+	queued := 0
+
+	for _, search := range searches {
+		// у пользователя может быть несколько подходящих поисков — уведомляем один раз
+		if notified[search.UserID] {
+			continue
+		}
+		notified[search.UserID] = true
+
+		created, err := s.queueAlert(&search, trip, now, dayStart)
+		if err != nil {
+			s.logger.Error("error queueing alert", slog.String("op", op), slog.Any("error", err))
+			continue
+		}
+		if created {
+			queued++
+		}
+	}
+
+	s.logger.Info("saved searches matched",
+		slog.String("op", op),
+		slog.Uint64("trip_id", uint64(trip.ID)),
+		slog.Int("matched", len(searches)),
+		slog.Int("queued", queued),
+	)
+}
+
+// queueAlert в одной транзакции проверяет дневной лимит, записывает отметку об уведомлении
+// и ставит его в outbox. Строка пользователя блокируется, поэтому параллельные
+// публикации поездок не могут вместе превысить лимит
+func (s *savedSearchService) queueAlert(search *models.SavedSearch, trip *models.Trip, now, dayStart time.Time) (bool, error) {
+	op := "service.saved_search.queue_alert"
+
+	created := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		searchRepo := s.searchRepo.WithDB(tx)
+
+		if err := searchRepo.LockAlerts(search.UserID); err != nil {
+			return err
+		}
+
+		sent, err := searchRepo.CountAlertsSince(search.UserID, dayStart)
+		if err != nil {
+			return err
+		}
+		if sent >= dailySearchAlertCap {
+			s.logger.Debug("daily alert cap reached",
+				slog.String("op", op),
+				slog.Uint64("user_id", uint64(search.UserID)),
+			)
+			return nil
+		}
+
+		// SentAt — момент передачи в outbox: дальше доставкой и повторами занимается воркер уведомлений
+		created, err = searchRepo.CreateAlert(&models.SearchAlert{
+			UserID:        search.UserID,
+			TripID:        trip.ID,
+			SavedSearchID: search.ID,
+			SentAt:        &now,
+		})
+		if err != nil || !created {
+			return err
+		}
+
+		return s.notifier.Enqueue(tx, search.UserID, constants.NotificationTripMatched, notify.MatchData(trip))
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
@@ -1,30 +1,49 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/events"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+var (
+	ErrTripCannotStart   = errors.New("trip cannot be started now")
+	ErrTripNotInProgress = errors.New("trip is not in progress")
+)
+
+// водитель может начать поездку не раньше, чем за это время до планового отправления
+const tripEarlyStartWindow = 30 * time.Minute
+
 type TripService interface {
 	Create(driverID uint, req *dto.TripCreateRequest) (*models.Trip, error)
 
-	List(filter dto.TripFilter) ([]models.Trip, error)
+	List(filter dto.TripFilter) ([]models.Trip, models.PageInfo, error)
 
 	GetByID(id uint) (*models.Trip, error)
 
 	Update(id uint, req dto.TripUpdateRequest) (*models.Trip, error)
 
 	Delete(id uint) error
+
+	Start(ctx context.Context, tripID, driverID uint) (*models.Trip, error)
+
+	Finish(ctx context.Context, tripID, driverID uint) (*models.Trip, error)
 }
 
 type tripService struct {
 	tripRepo repository.TripRepository
 	userRepo repository.UserRepository
 	carRepo  repository.CarRepository
+	matcher  SearchMatcher
+	bus      events.Bus
+	rule     constants.VerificationRule
 	logger   *slog.Logger
 }
 
@@ -32,11 +51,17 @@ func NewTripService(
 	tripRepo repository.TripRepository,
 	userRepo repository.UserRepository,
 	carRepo repository.CarRepository,
+	matcher SearchMatcher,
+	bus events.Bus,
+	rule constants.VerificationRule,
 	logger *slog.Logger) TripService {
 	return &tripService{
 		tripRepo: tripRepo,
 		userRepo: userRepo,
 		carRepo:  carRepo,
+		matcher:  matcher,
+		bus:      bus,
+		rule:     rule,
 		logger:   logger,
 	}
 }
@@ -54,6 +79,14 @@ func (s *tripService) Create(id uint, req *dto.TripCreateRequest) (*models.Trip,
 		return nil, err
 	}
 
+	if err := checkVerification(s.rule, driver, car); err != nil {
+		s.logger.Warn("unverified driver tried to publish a trip",
+			slog.Uint64("driver_id", uint64(id)),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
 	var trip = models.Trip{
 		DriverID:       driver.ID,
 		CarID:          car.ID,
@@ -66,16 +99,19 @@ func (s *tripService) Create(id uint, req *dto.TripCreateRequest) (*models.Trip,
 		Price:          req.Price,
 		TripStatus:     string(constants.TripPublished),
 		AvgRating:      0,
+		Preferences:    req.Preferences.Apply(driver.DefaultPreferences),
 	}
 
 	if err := s.tripRepo.Create(&trip); err != nil {
 		return nil, err
 	}
 
+	s.matcher.MatchTrip(&trip)
+
 	return &trip, nil
 }
 
-func (s *tripService) List(filter dto.TripFilter) ([]models.Trip, error) {
+func (s *tripService) List(filter dto.TripFilter) ([]models.Trip, models.PageInfo, error) {
 	return s.tripRepo.List(filter)
 }
 
@@ -132,6 +168,18 @@ func (s *tripService) Update(id uint, req dto.TripUpdateRequest) (*models.Trip,
 		return nil, err
 	}
 
+	if req.Preferences != nil {
+		trip.Preferences = req.Preferences.Apply(trip.Preferences)
+
+		if err := s.tripRepo.UpdatePreferences(trip.ID, trip.Preferences); err != nil {
+			s.logger.Error("failed to update trip preferences",
+				slog.Uint64("trip_id", uint64(id)),
+				slog.Any("error", err),
+			)
+			return nil, err
+		}
+	}
+
 	return trip, nil
 }
 
@@ -155,3 +203,72 @@ func (s *tripService) Delete(id uint) error {
 
 	return nil
 }
+
+func (s *tripService) Start(ctx context.Context, tripID, driverID uint) (*models.Trip, error) {
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.DriverID != driverID {
+		return nil, ErrNotTripDriver
+	}
+
+	now := time.Now().UTC()
+
+	if trip.TripStatus != string(constants.TripPublished) || now.Before(trip.StartTime.Add(-tripEarlyStartWindow)) {
+		return nil, ErrTripCannotStart
+	}
+
+	ok, err := s.tripRepo.MarkStarted(tripID, now)
+	if err != nil {
+		s.logger.Error("failed to start trip", slog.Uint64("trip_id", uint64(tripID)), slog.Any("error", err))
+		return nil, err
+	}
+	if !ok {
+		// статус успел смениться параллельно (например, воркером)
+		return nil, ErrTripCannotStart
+	}
+
+	trip.TripStatus = string(constants.TripInProgress)
+	trip.StartedAt = &now
+
+	s.bus.Publish(ctx, events.Event{Type: events.TripStarted, TripID: tripID, OccurredAt: now})
+
+	s.logger.Info("trip started by driver", slog.Uint64("trip_id", uint64(tripID)))
+	return trip, nil
+}
+
+func (s *tripService) Finish(ctx context.Context, tripID, driverID uint) (*models.Trip, error) {
+	trip, err := s.tripRepo.GetByID(tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.DriverID != driverID {
+		return nil, ErrNotTripDriver
+	}
+
+	if trip.TripStatus != string(constants.TripInProgress) {
+		return nil, ErrTripNotInProgress
+	}
+
+	now := time.Now().UTC()
+
+	ok, err := s.tripRepo.MarkFinished(tripID, now)
+	if err != nil {
+		s.logger.Error("failed to finish trip", slog.Uint64("trip_id", uint64(tripID)), slog.Any("error", err))
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTripNotInProgress
+	}
+
+	trip.TripStatus = string(constants.TripCompleted)
+	trip.FinishedAt = &now
+
+	s.bus.Publish(ctx, events.Event{Type: events.TripCompleted, TripID: tripID, OccurredAt: now})
+
+	s.logger.Info("trip finished by driver", slog.Uint64("trip_id", uint64(tripID)))
+	return trip, nil
+}
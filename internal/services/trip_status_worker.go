@@ -5,24 +5,34 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/events"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+// TripStatusWorker — запасной механизм смены статусов: основной путь — кнопки водителя
+// "начать" и "завершить". Воркер вмешивается, только если водитель не сделал этого
+// в течение grace после планового времени.
 type TripStatusWorker struct {
 	repo   repository.TripRepository
+	bus    events.Bus
 	logger *slog.Logger
 	tick   time.Duration
+	grace  time.Duration
 }
 
 func NewTripStatusWorker(
 	repo repository.TripRepository,
+	bus events.Bus,
 	logger *slog.Logger,
 	tick time.Duration,
+	grace time.Duration,
 ) *TripStatusWorker {
 	return &TripStatusWorker{
 		repo:   repo,
+		bus:    bus,
 		logger: logger,
 		tick:   tick,
+		grace:  grace,
 	}
 }
 
@@ -39,14 +49,34 @@ func (w *TripStatusWorker) Start(ctx context.Context) {
 				return
 
 			case <-ticker.C:
-				now := time.Now().UTC()
-				if err := w.repo.UpdateTripStatuses(now); err != nil {
-					w.logger.Error(
-						"failed to update trip statuses",
-						slog.Any("error", err),
-					)
-				}
+				w.runOnce(ctx, time.Now().UTC())
 			}
 		}
 	}()
 }
+
+func (w *TripStatusWorker) runOnce(ctx context.Context, now time.Time) {
+	started, completed, err := w.repo.UpdateTripStatuses(now, w.grace)
+	if err != nil {
+		w.logger.Error(
+			"failed to update trip statuses",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	if len(started) > 0 || len(completed) > 0 {
+		w.logger.Info("trip statuses updated",
+			slog.Int("started", len(started)),
+			slog.Int("completed", len(completed)),
+		)
+	}
+
+	for _, id := range started {
+		w.bus.Publish(ctx, events.Event{Type: events.TripStarted, TripID: id, OccurredAt: now})
+	}
+
+	for _, id := range completed {
+		w.bus.Publish(ctx, events.Event{Type: events.TripCompleted, TripID: id, OccurredAt: now})
+	}
+}
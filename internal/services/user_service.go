@@ -1,23 +1,58 @@
 package services
 
 import (
+	"errors"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/mutsaevz/team-5-ambitious/internal/auth"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/phone"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 )
 
+var (
+	ErrInvalidGender        = errors.New("invalid gender")
+	ErrInvalidPhone         = errors.New("invalid phone number")
+	ErrPhoneTaken           = errors.New("phone number is already registered")
+	ErrBioTooLong           = errors.New("bio must be at most 500 characters")
+	ErrInvalidBirthYear     = errors.New("invalid birth year")
+	ErrInvalidLanguage      = errors.New("languages must be two-letter ISO 639-1 codes")
+	ErrInvalidContactMethod = errors.New("invalid preferred contact method")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrCannotChangeOwnRole  = errors.New("administrators cannot change their own role")
+)
+
+const (
+	maxBioLength = 500
+	maxLanguages = 10
+
+	// minUserAge — пользоваться сервисом можно с этого возраста
+	minUserAge = 14
+)
+
 type UserService interface {
 	Create(req *dto.UserCreateRequest) (*models.User, error)
 
-	List(filter models.Page) ([]models.User, error)
+	List(filter models.Page) ([]models.User, models.PageInfo, error)
 
 	GetByID(id uint) (*models.User, error)
 
 	Update(id uint, req dto.UserUpdateRequest) (*models.User, error)
 
-	Delete(id uint) error
+	// PublicProfile — карточка пользователя для других: без телефона и баланса
+	PublicProfile(id uint) (*dto.PublicProfile, error)
+
+	// NormalizePhones переводит в E.164 телефоны, сохранённые до нормализации
+	NormalizePhones() (int, error)
+
+	// SetRole меняет роль пользователя; actorID — администратор, который это делает
+	SetRole(actorID, userID uint, role constants.Role) (*models.User, error)
 }
 
 type userService struct {
@@ -33,13 +68,42 @@ func NewUserService(userRepo repository.UserRepository, logger *slog.Logger) Use
 }
 
 func (s *userService) Create(req *dto.UserCreateRequest) (*models.User, error) {
+	if req.Gender != "" && !req.Gender.IsValid() {
+		return nil, ErrInvalidGender
+	}
+
+	phoneNumber, err := s.checkPhone(0, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := req.Profile.Apply(models.UserProfile{})
+	if err := validateProfile(&profile, time.Now()); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	var user = models.User{
-		Name:    req.Name,
-		Phone:   req.Phone,
-		Balance: req.Balance,
+		Name:         req.Name,
+		PasswordHash: passwordHash,
+		Phone:        phoneNumber,
+		Balance:      req.Balance,
+		Gender:       req.Gender,
+		UserProfile:  profile,
+
+		// по умолчанию музыка в поездках разрешена, остальное водитель включает сам
+		DefaultPreferences: req.DefaultPreferences.Apply(models.TripPreferences{MusicAllowed: true}),
 	}
 
 	if err := s.repo.Create(&user); err != nil {
+		// номер заняли параллельным запросом между проверкой и вставкой
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrPhoneTaken
+		}
 		s.logger.Error("error adding user",
 			slog.Any("error", err),
 		)
@@ -49,16 +113,16 @@ func (s *userService) Create(req *dto.UserCreateRequest) (*models.User, error) {
 	return &user, nil
 }
 
-func (s *userService) List(filter models.Page) ([]models.User, error) {
-	users, err := s.repo.List(filter)
+func (s *userService) List(filter models.Page) ([]models.User, models.PageInfo, error) {
+	users, info, err := s.repo.List(filter)
 	if err != nil {
 		s.logger.Error("user list error",
 			slog.Any("error", err),
 		)
-		return nil, err
+		return nil, models.PageInfo{}, err
 	}
 
-	return users, nil
+	return users, info, nil
 }
 
 func (s *userService) GetByID(id uint) (*models.User, error) {
@@ -88,29 +152,179 @@ func (s *userService) Update(id uint, req dto.UserUpdateRequest) (*models.User,
 	}
 
 	if req.Phone != nil {
-		user.Phone = *req.Phone
+		if user.Phone, err = s.checkPhone(id, *req.Phone); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Profile != nil {
+		user.UserProfile = req.Profile.Apply(user.UserProfile)
+		if err := validateProfile(&user.UserProfile, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Gender != nil {
+		if !req.Gender.IsValid() {
+			return nil, ErrInvalidGender
+		}
+		user.Gender = *req.Gender
 	}
 
 	if err := s.repo.Update(id, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrPhoneTaken
+		}
 		s.logger.Error("error saving changes",
-			slog.String("user_name", *req.Name),
+			slog.Uint64("user_id", uint64(id)),
 			slog.Any("error", err),
 		)
 		return nil, err
 	}
 
+	// пустые значения профиля Updates пропускает — пишем профиль отдельно
+	if req.Profile != nil {
+		if err := s.repo.UpdateProfile(id, user.UserProfile); err != nil {
+			s.logger.Error("error saving profile",
+				slog.Uint64("user_id", uint64(id)),
+				slog.Any("error", err),
+			)
+			return nil, err
+		}
+	}
+
+	if req.DefaultPreferences != nil {
+		user.DefaultPreferences = req.DefaultPreferences.Apply(user.DefaultPreferences)
+
+		if err := s.repo.UpdateDefaultPreferences(id, user.DefaultPreferences); err != nil {
+			s.logger.Error("error saving default preferences",
+				slog.Uint64("user_id", uint64(id)),
+				slog.Any("error", err),
+			)
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
 
-func (s *userService) Delete(id uint) error {
-	err := s.repo.Delete(id)
+func (s *userService) SetRole(actorID, userID uint, role constants.Role) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	// иначе последний администратор может случайно лишить сервис админки
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	if err := s.repo.SetRole(userID, role); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("user role changed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("actor_id", uint64(actorID)),
+		slog.String("role", string(role)),
+	)
+
+	return s.repo.GetByID(userID)
+}
+
+func (s *userService) PublicProfile(id uint) (*dto.PublicProfile, error) {
+	user, err := s.repo.GetByID(id)
 	if err != nil {
-		s.logger.Error("failed to delete user",
-			slog.Uint64("user_id", uint64(id)),
-			slog.Any("error", err),
-		)
-		return err
+		return nil, err
+	}
+
+	profile := dto.NewPublicProfile(user, time.Now())
+	return &profile, nil
+}
+
+func (s *userService) NormalizePhones() (int, error) {
+	users, err := s.repo.ListUnnormalizedPhones()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, user := range users {
+		normalized, err := phone.Normalize(user.Phone)
+		if err != nil {
+			s.logger.Warn("cannot normalize phone, left as is", slog.Uint64("user_id", uint64(user.ID)))
+			continue
+		}
+
+		// один и тот же номер в разных записях — такие дубли разбираются вручную
+		if owner, err := s.repo.PhoneOwner(normalized); err == nil && owner != user.ID {
+			s.logger.Warn("normalized phone belongs to another user, left as is",
+				slog.Uint64("user_id", uint64(user.ID)),
+				slog.Uint64("owner_id", uint64(owner)),
+			)
+			continue
+		} else if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return count, err
+		}
+
+		if err := s.repo.UpdatePhone(user.ID, normalized); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// checkPhone нормализует номер и проверяет, что он не занят другим пользователем,
+// в том числе удалённым
+func (s *userService) checkPhone(userID uint, raw string) (string, error) {
+	normalized, err := phone.Normalize(raw)
+	if err != nil {
+		return "", ErrInvalidPhone
+	}
+
+	owner, err := s.repo.PhoneOwner(normalized)
+	if err == nil && owner != userID {
+		return "", ErrPhoneTaken
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+
+	return normalized, nil
+}
+
+// validateProfile проверяет поля профиля и приводит коды языков к нижнему регистру без повторов
+func validateProfile(p *models.UserProfile, now time.Time) error {
+	p.Bio = strings.TrimSpace(p.Bio)
+	if utf8.RuneCountInString(p.Bio) > maxBioLength {
+		return ErrBioTooLong
+	}
+
+	if p.BirthYear != nil && (*p.BirthYear < 1900 || *p.BirthYear > now.Year()-minUserAge) {
+		return ErrInvalidBirthYear
+	}
+
+	if p.PreferredContact != "" && !p.PreferredContact.IsValid() {
+		return ErrInvalidContactMethod
+	}
+
+	if len(p.Languages) > maxLanguages {
+		return ErrInvalidLanguage
+	}
+
+	languages := make(models.Languages, 0, len(p.Languages))
+	for _, code := range p.Languages {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if len(code) != 2 || code[0] < 'a' || code[0] > 'z' || code[1] < 'a' || code[1] > 'z' {
+			return ErrInvalidLanguage
+		}
+		if !slices.Contains(languages, code) {
+			languages = append(languages, code)
+		}
 	}
+	p.Languages = languages
 
 	return nil
 }
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+)
+
+// Причины, по которым водитель не может опубликовать поездку
+var (
+	ErrDriverNotVerified = errors.New("driver license has not been verified")
+	ErrCarNotVerified    = errors.New("car registration has not been verified")
+)
+
+// checkVerification возвращает ошибку, если водитель или его автомобиль не прошли
+// проверку, которую требует rule
+func checkVerification(rule constants.VerificationRule, driver *models.User, car *models.Car) error {
+	switch rule {
+	case constants.VerificationDriver, constants.VerificationDriverAndCar:
+		if !driver.DriverVerified {
+			return ErrDriverNotVerified
+		}
+	}
+
+	if rule == constants.VerificationDriverAndCar && !car.Verified {
+		return ErrCarNotVerified
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/storage"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDocumentType     = errors.New("invalid document type")
+	ErrDocumentTooLarge        = errors.New("document file is too large")
+	ErrUnsupportedDocument     = errors.New("unsupported document type, expected JPEG, PNG or PDF")
+	ErrVerificationPending     = errors.New("a document of this type is already awaiting review")
+	ErrDocumentAlreadyReviewed = errors.New("document has already been reviewed")
+	ErrRejectReasonNeeded      = errors.New("reason is required to reject a document")
+	ErrDocumentOutdated        = errors.New("car license plate changed after the document was uploaded")
+)
+
+const (
+	maxDocumentBytes = 10 << 20
+	documentURLTTL   = 5 * time.Minute
+)
+
+// allowedDocumentTypes — тип содержимого и расширение ключа в хранилище
+var allowedDocumentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+type VerificationService interface {
+	// Submit принимает документ водителя и ставит его в очередь на проверку
+	Submit(ctx context.Context, userID uint, docType constants.DocumentType, file io.Reader) (*models.VerificationDocument, error)
+
+	ListByUser(userID uint) ([]models.VerificationDocument, error)
+
+	ListForReview(status constants.VerificationStatus, filter models.Page) ([]models.VerificationDocument, models.PageInfo, error)
+
+	// DocumentURL — подписанная ссылка на файл документа для администратора
+	DocumentURL(ctx context.Context, id uint) (string, error)
+
+	Approve(id, reviewerID uint) (*models.VerificationDocument, error)
+
+	Reject(id, reviewerID uint, reason string) (*models.VerificationDocument, error)
+}
+
+type verificationService struct {
+	verificationRepo repository.VerificationRepository
+	userRepo         repository.UserRepository
+	carRepo          repository.CarRepository
+	blobs            storage.BlobStorage
+	db               *gorm.DB
+	logger           *slog.Logger
+}
+
+func NewVerificationService(
+	verificationRepo repository.VerificationRepository,
+	userRepo repository.UserRepository,
+	carRepo repository.CarRepository,
+	blobs storage.BlobStorage,
+	db *gorm.DB,
+	logger *slog.Logger,
+) VerificationService {
+	return &verificationService{
+		verificationRepo: verificationRepo,
+		userRepo:         userRepo,
+		carRepo:          carRepo,
+		blobs:            blobs,
+		db:               db,
+		logger:           logger,
+	}
+}
+
+func (s *verificationService) Submit(ctx context.Context, userID uint, docType constants.DocumentType, file io.Reader) (*models.VerificationDocument, error) {
+	op := "service.verification.submit"
+
+	if !docType.IsValid() {
+		return nil, ErrInvalidDocumentType
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	doc := &models.VerificationDocument{
+		UserID: userID,
+		Type:   docType,
+		Status: constants.VerificationPending,
+	}
+
+	// СТС привязывается к текущему автомобилю водителя и его номеру
+	if docType == constants.DocumentCarRegistration {
+		car, err := s.carRepo.GetByOwner(userID)
+		if err != nil {
+			return nil, err
+		}
+		doc.CarID = &car.ID
+		doc.LicensePlate = car.LicensePlate
+	}
+
+	pending, err := s.verificationRepo.HasPending(userID, docType)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrVerificationPending
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(file, maxDocumentBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxDocumentBytes {
+		return nil, ErrDocumentTooLarge
+	}
+
+	// тип определяем по содержимому, а не по имени файла или заголовку клиента
+	contentType := http.DetectContentType(raw)
+	ext, ok := allowedDocumentTypes[contentType]
+	if !ok {
+		s.logger.Warn("rejected document upload", slog.String("op", op), slog.String("content_type", contentType))
+		return nil, ErrUnsupportedDocument
+	}
+
+	key, err := storage.NewKey(fmt.Sprintf("users/%d/documents/%s", userID, docType), ext)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(raw), int64(len(raw)), contentType); err != nil {
+		s.logger.Error("error storing document", slog.String("op", op), slog.Any("error", err))
+		return nil, err
+	}
+
+	doc.Key = key
+	doc.ContentType = contentType
+
+	if err := s.verificationRepo.Create(doc); err != nil {
+		if delErr := s.blobs.Delete(ctx, key); delErr != nil {
+			s.logger.Warn("failed to delete blob", slog.String("key", key), slog.Any("error", delErr))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("verification document submitted",
+		slog.String("op", op),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("type", string(docType)),
+	)
+
+	return doc, nil
+}
+
+func (s *verificationService) ListByUser(userID uint) ([]models.VerificationDocument, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	return s.verificationRepo.ListByUser(userID)
+}
+
+func (s *verificationService) ListForReview(status constants.VerificationStatus, filter models.Page) ([]models.VerificationDocument, models.PageInfo, error) {
+	return s.verificationRepo.ListForReview(status, filter)
+}
+
+func (s *verificationService) DocumentURL(ctx context.Context, id uint) (string, error) {
+	doc, err := s.verificationRepo.GetByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	return s.blobs.SignedURL(ctx, doc.Key, documentURLTTL)
+}
+
+func (s *verificationService) Approve(id, reviewerID uint) (*models.VerificationDocument, error) {
+	op := "service.verification.approve"
+
+	var doc *models.VerificationDocument
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		repo := s.verificationRepo.WithDB(tx)
+
+		var err error
+		doc, err = repo.GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		if doc.Status != constants.VerificationPending {
+			return ErrDocumentAlreadyReviewed
+		}
+
+		now := time.Now()
+
+		switch doc.Type {
+		case constants.DocumentDriverLicense:
+			if err := repo.SetDriverVerified(doc.UserID, now); err != nil {
+				return err
+			}
+		case constants.DocumentCarRegistration:
+			if doc.CarID == nil {
+				return ErrDocumentOutdated
+			}
+
+			// читаем в той же транзакции, чтобы сверка номера и отметка о проверке шли вместе
+			car, err := s.carRepo.WithDB(tx).GetByID(*doc.CarID)
+			if err != nil {
+				return err
+			}
+			if car.OwnerID != doc.UserID || car.LicensePlate != doc.LicensePlate {
+				return ErrDocumentOutdated
+			}
+
+			if err := repo.SetCarVerified(car.ID, now); err != nil {
+				return err
+			}
+		}
+
+		ok, err := repo.SetStatus(id, constants.VerificationPending, constants.VerificationApproved, "", reviewerID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrDocumentAlreadyReviewed
+		}
+
+		doc, err = repo.GetByID(id)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("verification document approved",
+		slog.String("op", op),
+		slog.Uint64("document_id", uint64(id)),
+		slog.Uint64("reviewer_id", uint64(reviewerID)),
+	)
+
+	return doc, nil
+}
+
+func (s *verificationService) Reject(id, reviewerID uint, reason string) (*models.VerificationDocument, error) {
+	op := "service.verification.reject"
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRejectReasonNeeded
+	}
+
+	ok, err := s.verificationRepo.SetStatus(id, constants.VerificationPending, constants.VerificationRejected, reason, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		// либо документа нет, либо его уже проверили
+		if _, err := s.verificationRepo.GetByID(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrDocumentAlreadyReviewed
+	}
+
+	s.logger.Info("verification document rejected",
+		slog.String("op", op),
+		slog.Uint64("document_id", uint64(id)),
+		slog.Uint64("reviewer_id", uint64(reviewerID)),
+	)
+
+	return s.verificationRepo.GetByID(id)
+}
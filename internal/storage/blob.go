@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrObjectNotFound   = errors.New("object not found")
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// BlobStorage — хранилище бинарных объектов (фото, документы).
+// Ключи имеют вид "users/12/avatar/<uuid>.jpg" и не должны содержать "..".
+type BlobStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL возвращает ссылку на объект, действующую в течение ttl
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
@@ -0,0 +1,288 @@
+package transports
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/auth"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/policy"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+	"gorm.io/gorm"
+)
+
+const principalKey = "principal"
+
+var errUnauthenticated = errors.New("authentication required")
+
+// accessRule решает, пускать ли запрос к ручке; p == nil — анонимный запрос
+type accessRule func(ctx *gin.Context, p *auth.Principal) error
+
+// accessControl проверяет токен, применяет правило ручки и пишет журнал действий в /admin
+type accessControl struct {
+	auth   services.AuthService
+	policy *policy.Policy
+	audit  services.AuditService
+	logger *slog.Logger
+}
+
+// middleware ищет правило по методу и шаблону пути. Ручка без правила закрыта:
+// новые ручки не должны случайно оказаться открытыми.
+func (a *accessControl) middleware(rules map[string]accessRule) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// несуществующий путь — пусть gin ответит 404
+		if ctx.FullPath() == "" {
+			ctx.Next()
+			return
+		}
+
+		rule, ok := rules[ctx.Request.Method+" "+ctx.FullPath()]
+		if !ok {
+			a.logger.Error("no access rule for route",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+			)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": policy.ErrForbidden.Error()})
+			return
+		}
+
+		p, err := a.authenticate(ctx)
+		if err != nil {
+			a.abort(ctx, err)
+			return
+		}
+
+		if p != nil {
+			ctx.Set(principalKey, p)
+		}
+
+		if err := rule(ctx, p); err != nil {
+			a.abort(ctx, err)
+			return
+		}
+
+		ctx.Next()
+
+		if p != nil && isAudited(ctx) {
+			a.record(ctx, p)
+		}
+	}
+}
+
+func (a *accessControl) authenticate(ctx *gin.Context) (*auth.Principal, error) {
+	header := ctx.GetHeader("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return a.auth.Authenticate(strings.TrimSpace(token), clientInfo(ctx))
+}
+
+func clientInfo(ctx *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+func (a *accessControl) abort(ctx *gin.Context, err error) {
+	var param paramError
+
+	switch {
+	case errors.Is(err, errUnauthenticated),
+		errors.Is(err, auth.ErrInvalidToken),
+		errors.Is(err, auth.ErrTokenExpired),
+		errors.Is(err, services.ErrSessionClosed):
+		ctx.Header("WWW-Authenticate", "Bearer")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrForbidden):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.As(err, &param):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		a.logger.Error("access check failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// isAudited — в журнал попадают успешные изменения через админские ручки
+func isAudited(ctx *gin.Context) bool {
+	return strings.HasPrefix(ctx.FullPath(), "/admin/") &&
+		ctx.Request.Method != http.MethodGet &&
+		ctx.Writer.Status() < http.StatusBadRequest
+}
+
+func (a *accessControl) record(ctx *gin.Context, p *auth.Principal) {
+	// "/admin/reviews/:id/hide" → "reviews"
+	targetType, _, _ := strings.Cut(strings.TrimPrefix(ctx.FullPath(), "/admin/"), "/")
+
+	entry := &models.AuditEntry{
+		ActorID:    p.UserID,
+		ActorRole:  p.Role,
+		Action:     ctx.Request.Method + " " + ctx.FullPath(),
+		TargetType: targetType,
+		Status:     ctx.Writer.Status(),
+		IP:         ctx.ClientIP(),
+	}
+
+	if id, err := strconv.ParseUint(ctx.Param("id"), 10, 64); err == nil {
+		targetID := uint(id)
+		entry.TargetID = &targetID
+	}
+
+	a.audit.Record(entry)
+}
+
+// checkAccessRules падает при старте, если у какой-то ручки нет правила доступа
+func checkAccessRules(routes gin.RoutesInfo, rules map[string]accessRule) {
+	var missing []string
+
+	for _, route := range routes {
+		if _, ok := rules[route.Method+" "+route.Path]; !ok {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		panic(fmt.Sprintf("transports: no access rule for %s", strings.Join(missing, ", ")))
+	}
+}
+
+// principal — пользователь текущего запроса, nil для анонимного
+func principal(ctx *gin.Context) *auth.Principal {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	p, _ := value.(*auth.Principal)
+	return p
+}
+
+// actingAs проверяет, что идентификатор действующего лица из тела запроса — это сам
+// пользователь токена; иначе отвечает 403
+func actingAs(ctx *gin.Context, userID uint) bool {
+	p := principal(ctx)
+	if p == nil || policy.Self(p, userID) != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "you can only act on your own behalf"})
+		return false
+	}
+	return true
+}
+
+type paramError struct {
+	param string
+}
+
+func (e paramError) Error() string {
+	return "invalid " + e.param
+}
+
+func paramID(ctx *gin.Context, param string) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param(param), 10, 64)
+	if err != nil {
+		return 0, paramError{param: param}
+	}
+	return uint(id), nil
+}
+
+// Правила доступа
+
+func public(*gin.Context, *auth.Principal) error {
+	return nil
+}
+
+func authenticated(_ *gin.Context, p *auth.Principal) error {
+	if p == nil {
+		return errUnauthenticated
+	}
+	return nil
+}
+
+func roles(allowed ...constants.Role) accessRule {
+	return func(_ *gin.Context, p *auth.Principal) error {
+		if p == nil {
+			return errUnauthenticated
+		}
+		return policy.HasRole(p, allowed...)
+	}
+}
+
+var (
+	staffOnly = roles(constants.RoleSupport, constants.RoleAdmin)
+	adminOnly = roles(constants.RoleAdmin)
+)
+
+// userRule применяет проверку к пользователю из параметра пути
+func userRule(param string, check func(p *auth.Principal, userID uint) error) accessRule {
+	return func(ctx *gin.Context, p *auth.Principal) error {
+		if p == nil {
+			return errUnauthenticated
+		}
+		id, err := paramID(ctx, param)
+		if err != nil {
+			return err
+		}
+		return check(p, id)
+	}
+}
+
+func self(param string) accessRule {
+	return userRule(param, policy.Self)
+}
+
+func selfOrStaff(param string) accessRule {
+	return userRule(param, policy.SelfOrStaff)
+}
+
+func (a *accessControl) tripDriver(param string) accessRule {
+	return userRule(param, a.policy.ManageTrip)
+}
+
+func (a *accessControl) carOwner(param string) accessRule {
+	return userRule(param, a.policy.ManageCar)
+}
+
+func (a *accessControl) bookingPassenger(param string) accessRule {
+	return userRule(param, a.policy.CancelBooking)
+}
+
+func (a *accessControl) bookingDriver(param string) accessRule {
+	return userRule(param, a.policy.DecideBooking)
+}
+
+func (a *accessControl) bookingViewer(param string) accessRule {
+	return userRule(param, a.policy.ViewBooking)
+}
+
+// allOf пропускает запрос, только если пропускают все правила
+func allOf(rules ...accessRule) accessRule {
+	return func(ctx *gin.Context, p *auth.Principal) error {
+		for _, rule := range rules {
+			if err := rule(ctx, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type AccountHandler struct {
+	service services.AccountService
+	logger  *slog.Logger
+}
+
+func NewAccountHandler(service services.AccountService, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *AccountHandler) RegisterRoutes(ctx *gin.Engine) {
+	ctx.POST("/users/:id/exports", h.RequestExport)
+	ctx.GET("/users/:id/exports", h.ListExports)
+	ctx.GET("/users/:id/exports/:export_id", h.GetExport)
+
+	ctx.DELETE("/users/:id", h.Erase)
+	ctx.DELETE("/admin/users/:id", h.Erase)
+}
+
+// POST /users/:id/exports — архив собирается в фоне, статус смотрим через GET
+func (h *AccountHandler) RequestExport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	export, err := h.service.RequestExport(uint(id))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, export)
+}
+
+// GET /users/:id/exports
+func (h *AccountHandler) ListExports(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	exports, err := h.service.ListExports(uint(id))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writeList(ctx, exports)
+}
+
+// GET /users/:id/exports/:export_id
+func (h *AccountHandler) GetExport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	exportID, err := strconv.ParseUint(ctx.Param("export_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid export id"})
+		return
+	}
+
+	export, err := h.service.GetExport(ctx.Request.Context(), uint(id), uint(exportID))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, export)
+}
+
+// DELETE /users/:id, DELETE /admin/users/:id?forfeitBalance=true
+func (h *AccountHandler) Erase(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	forfeit, ok := queryBool(ctx, "forfeitBalance")
+	if !ok {
+		return
+	}
+
+	erasure, err := h.service.Erase(ctx.Request.Context(), principal(ctx).UserID, uint(id), forfeit != nil && *forfeit)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, erasure)
+}
+
+func (h *AccountHandler) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrExportNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, services.ErrExportInProgress),
+		errors.Is(err, services.ErrOutstandingBalance),
+		errors.Is(err, services.ErrTripInProgress):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("account request failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,137 @@
+package transports
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+// AdminHandler — управление ролями и паролями пользователей и журнал действий сотрудников
+type AdminHandler struct {
+	users   services.UserService
+	auth    services.AuthService
+	audit   services.AuditService
+	cursors *pagination.Codec
+	logger  *slog.Logger
+}
+
+func NewAdminHandler(
+	users services.UserService,
+	auth services.AuthService,
+	audit services.AuditService,
+	cursors *pagination.Codec,
+	logger *slog.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		users:   users,
+		auth:    auth,
+		audit:   audit,
+		cursors: cursors,
+		logger:  logger,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(ctx *gin.Engine) {
+	api := ctx.Group("/admin")
+
+	api.PUT("/users/:id/role", h.SetRole)
+	api.PUT("/users/:id/password", h.ResetPassword)
+	api.GET("/audit", h.Audit)
+}
+
+// PUT /admin/users/:id/role
+func (h *AdminHandler) SetRole(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.RoleUpdateRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.users.SetRole(principal(ctx).UserID, uint(id), req.Role)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// PUT /admin/users/:id/password
+func (h *AdminHandler) ResetPassword(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.PasswordResetRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.auth.ResetPassword(uint(id), req.Password); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "password reset"})
+}
+
+// GET /admin/audit?actorId=&targetType=&targetId=
+func (h *AdminHandler) Audit(ctx *gin.Context) {
+	var (
+		filter dto.AuditFilter
+		ok     bool
+	)
+
+	if filter.ActorID, ok = queryID(ctx, "actorId"); !ok {
+		return
+	}
+	if filter.TargetID, ok = queryID(ctx, "targetId"); !ok {
+		return
+	}
+	filter.TargetType = ctx.Query("targetType")
+
+	page, ok := queryPage(ctx, h.cursors, cursorScopeAudit)
+	if !ok {
+		return
+	}
+
+	filter.Page, filter.PageSize, filter.After = page.Page, page.PageSize, page.After
+
+	entries, info, err := h.audit.List(filter)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writePage(ctx, h.cursors, cursorScopeAudit, entries, info)
+}
+
+func (h *AdminHandler) respondError(ctx *gin.Context, err error) {
+	if status, ok := userErrorStatus(err); ok {
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Error("admin request failed",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+		slog.Any("error", err),
+	)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
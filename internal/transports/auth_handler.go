@@ -0,0 +1,197 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type AuthHandler struct {
+	service services.AuthService
+	users   services.UserService
+	logger  *slog.Logger
+}
+
+func NewAuthHandler(service services.AuthService, users services.UserService, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		users:   users,
+		logger:  logger,
+	}
+}
+
+func (h *AuthHandler) RegisterRoutes(ctx *gin.Engine) {
+	api := ctx.Group("/auth")
+	{
+		api.POST("/login", h.Login)
+		api.POST("/refresh", h.Refresh)
+		api.POST("/logout", h.Logout)
+		api.POST("/logout-all", h.LogoutAll)
+		api.GET("/me", h.Me)
+	}
+
+	ctx.PUT("/users/:id/password", h.ChangePassword)
+	ctx.GET("/users/:id/sessions", h.Sessions)
+	ctx.DELETE("/users/:id/sessions/:session_id", h.RevokeSession)
+}
+
+// POST /auth/login
+func (h *AuthHandler) Login(ctx *gin.Context) {
+	var req dto.LoginRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	client := clientInfo(ctx)
+	client.DeviceName = req.DeviceName
+
+	token, err := h.service.Login(req.Phone, req.Password, client)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token)
+}
+
+// POST /auth/refresh
+func (h *AuthHandler) Refresh(ctx *gin.Context) {
+	var req dto.RefreshRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	token, err := h.service.Refresh(req.RefreshToken, clientInfo(ctx))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token)
+}
+
+// POST /auth/logout
+func (h *AuthHandler) Logout(ctx *gin.Context) {
+	if err := h.service.Logout(principal(ctx)); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+// POST /auth/logout-all — выход на всех устройствах, включая текущее
+func (h *AuthHandler) LogoutAll(ctx *gin.Context) {
+	count, err := h.service.LogoutAll(principal(ctx).UserID)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "logged out", "sessions": count})
+}
+
+// GET /users/:id/sessions
+func (h *AuthHandler) Sessions(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	sessions, err := h.service.ListSessions(uint(id), principal(ctx).SessionID)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writeList(ctx, sessions)
+}
+
+// DELETE /users/:id/sessions/:session_id
+func (h *AuthHandler) RevokeSession(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(ctx.Param("session_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	if err := h.service.RevokeSession(uint(id), uint(sessionID)); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
+
+// GET /auth/me
+func (h *AuthHandler) Me(ctx *gin.Context) {
+	user, err := h.users.GetByID(principal(ctx).UserID)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// PUT /users/:id/password
+func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.PasswordChangeRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ChangePassword(uint(id), principal(ctx).SessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "password changed"})
+}
+
+func (h *AuthHandler) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials),
+		errors.Is(err, services.ErrInvalidRefreshToken),
+		errors.Is(err, services.ErrRefreshTokenReused):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSessionNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrWrongPassword):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	default:
+		h.logger.Error("auth request failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
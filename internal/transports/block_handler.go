@@ -0,0 +1,112 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type BlockHandler struct {
+	service services.BlockService
+	logger  *slog.Logger
+}
+
+func NewBlockHandler(service services.BlockService, logger *slog.Logger) *BlockHandler {
+	return &BlockHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *BlockHandler) RegisterRoutes(ctx *gin.Engine) {
+	ctx.GET("/users/:id/blocks", h.List)
+	ctx.POST("/users/:id/blocks/:blocked_id", h.Block)
+	ctx.DELETE("/users/:id/blocks/:blocked_id", h.Unblock)
+}
+
+// GET /users/:id/blocks
+func (h *BlockHandler) List(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	blocks, err := h.service.List(uint(id))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writeList(ctx, blocks)
+}
+
+// POST /users/:id/blocks/:blocked_id
+func (h *BlockHandler) Block(ctx *gin.Context) {
+	blockerID, blockedID, ok := h.pair(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.Block(blockerID, blockedID)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// DELETE /users/:id/blocks/:blocked_id
+func (h *BlockHandler) Unblock(ctx *gin.Context) {
+	blockerID, blockedID, ok := h.pair(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unblock(blockerID, blockedID); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "unblocked"})
+}
+
+func (h *BlockHandler) pair(ctx *gin.Context) (uint, uint, bool) {
+	blockerID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, 0, false
+	}
+
+	blockedID, err := strconv.ParseUint(ctx.Param("blocked_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid blocked user id"})
+		return 0, 0, false
+	}
+
+	return uint(blockerID), uint(blockedID), true
+}
+
+func (h *BlockHandler) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, services.ErrNotBlocked):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCannotBlockSelf):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyBlocked):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("block request failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -1,24 +1,30 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
-	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
+	"gorm.io/gorm"
 )
 
 type BookingHandler struct {
 	service services.BookingService
+	cursors *pagination.Codec
 	logger  *slog.Logger
 }
 
-func NewBookingHandler(service services.BookingService, logger *slog.Logger) *BookingHandler {
+func NewBookingHandler(service services.BookingService, cursors *pagination.Codec, logger *slog.Logger) *BookingHandler {
 	return &BookingHandler{
 		service: service,
+		cursors: cursors,
 		logger:  logger,
 	}
 }
@@ -27,12 +33,22 @@ func (h BookingHandler) RegisterRoutes(ctx *gin.Engine) {
 	api := ctx.Group("/bookings")
 	{
 		api.POST("/", h.Create)
-		api.GET("/", h.List)
 		api.GET("/:id", h.GetByID)
 		api.GET("driver/:driver_id/trip/:trip_id/pending", h.GetAllPendingBookingsByTripID)
-		api.PATCH("/:id", h.Update)
-		api.DELETE("/:id", h.Delete)
+		api.PATCH("/:id", h.Decide)
+		api.POST("/:id/cancel", h.Cancel)
 	}
+
+	admin := ctx.Group("/admin/bookings")
+	{
+		admin.GET("", h.List)
+		admin.PATCH("/:id", h.Update)
+		admin.DELETE("/:id", h.Delete)
+	}
+
+	ctx.GET("/users/:id/bookings", h.ListByPassenger)
+	ctx.POST("/trips/:id/bookings/:booking_id/check-in", h.CheckIn)
+	ctx.POST("/trips/:id/bookings/:booking_id/no-show", h.MarkNoShow)
 }
 
 func (h *BookingHandler) Create(ctx *gin.Context) {
@@ -54,8 +70,27 @@ func (h *BookingHandler) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !actingAs(ctx, input.PassengerID) {
+		return
+	}
+
 	booking, err := h.service.Create(&input)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip or passenger not found"})
+			return
+		}
+
+		if errors.Is(err, services.ErrWomenOnlyTrip) || errors.Is(err, services.ErrUserBlocked) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, services.ErrNotEnoughSeats) || errors.Is(err, services.ErrTripNotBookable) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
 		h.logger.Error("error adding booking",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -77,23 +112,12 @@ func (h *BookingHandler) List(ctx *gin.Context) {
 		slog.String("path", ctx.FullPath()),
 	)
 
-	var filter models.Page
-
-	if pageStr := ctx.Query("page"); pageStr != "" {
-		page, err := strconv.Atoi(pageStr)
-		if err == nil {
-			filter.Page = page
-		}
-	}
-
-	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
-		pageSize, err := strconv.Atoi(pageSizeStr)
-		if err == nil {
-			filter.PageSize = pageSize
-		}
+	filter, ok := queryPage(ctx, h.cursors, cursorScopeBookings)
+	if !ok {
+		return
 	}
 
-	bookings, err := h.service.List(filter)
+	bookings, info, err := h.service.List(filter)
 
 	if err != nil {
 		h.logger.Error("error getting bookings",
@@ -107,7 +131,7 @@ func (h *BookingHandler) List(ctx *gin.Context) {
 	}
 
 	h.logger.Info("bookings retrieved successfully")
-	ctx.JSON(http.StatusOK, bookings)
+	writePage(ctx, h.cursors, cursorScopeBookings, bookings, info)
 }
 
 func (h *BookingHandler) GetAllPendingBookingsByTripID(ctx *gin.Context) {
@@ -146,7 +170,7 @@ func (h *BookingHandler) GetAllPendingBookingsByTripID(ctx *gin.Context) {
 	}
 
 	h.logger.Info("pending bookings retrieved successfully")
-	ctx.JSON(http.StatusOK, bookings)
+	writeList(ctx, bookings)
 }
 
 func (h *BookingHandler) GetByID(ctx *gin.Context) {
@@ -237,6 +261,67 @@ func (h *BookingHandler) Update(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, booking)
 }
 
+// PATCH /bookings/:id — водитель одобряет или отклоняет заявку
+func (h *BookingHandler) Decide(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	var input dto.BookingDecisionRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	decide := h.service.Rejected
+	if input.Status == constants.BookingApproved {
+		decide = h.service.Approve
+	}
+
+	if err := decide(uint(id), principal(ctx).UserID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrNotTripDriver):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrBookingNotPending), errors.Is(err, services.ErrNotEnoughSeats),
+			errors.Is(err, services.ErrTripNotBookable):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("error deciding booking",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.Any("error", err.Error()),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	booking, err := h.service.GetByID(uint(id))
+	if err != nil {
+		h.logger.Error("error getting booking by ID",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err.Error()),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("booking decided successfully")
+	ctx.JSON(http.StatusOK, booking)
+}
+
 func (h *BookingHandler) Delete(ctx *gin.Context) {
 
 	h.logger.Info("handler called",
@@ -273,3 +358,172 @@ func (h *BookingHandler) Delete(ctx *gin.Context) {
 	h.logger.Info("booking deleted successfully")
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+// GET /users/:id/bookings?status=approved&when=upcoming
+func (h *BookingHandler) ListByPassenger(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	filter := dto.PassengerBookingFilter{PassengerID: uint(id)}
+
+	if statusStr := ctx.Query("status"); statusStr != "" {
+		status := constants.BookingStatus(statusStr)
+		switch status {
+		case constants.BookingPending, constants.BookingApproved,
+			constants.BookingRejected, constants.BookingCancelled, constants.BookingNoShow:
+			filter.Status = &status
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+	}
+
+	switch when := ctx.Query("when"); when {
+	case "", dto.BookingWhenUpcoming, dto.BookingWhenPast:
+		filter.When = when
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "when must be upcoming or past"})
+		return
+	}
+
+	// upcoming и past отсортированы в разные стороны, поэтому курсоры у них разные
+	scope := cursorScopePassengerBookings + ":" + filter.When
+
+	page, ok := queryPage(ctx, h.cursors, scope)
+	if !ok {
+		return
+	}
+
+	filter.Page, filter.PageSize, filter.After = page.Page, page.PageSize, page.After
+
+	bookings, info, err := h.service.ListByPassenger(filter)
+	if err != nil {
+		h.logger.Error("error getting passenger bookings",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err.Error()),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	writePage(ctx, h.cursors, scope, bookings, info)
+}
+
+// POST /bookings/:id/cancel
+func (h *BookingHandler) Cancel(ctx *gin.Context) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID parameter"})
+		return
+	}
+
+	var input dto.BookingCancelRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	resp, err := h.service.Cancel(uint(id), input.PassengerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		case errors.Is(err, services.ErrNotBookingOwner):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrCannotCancel):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("error cancelling booking",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.Any("error", err.Error()),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("booking cancelled successfully")
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// POST /trips/:id/bookings/:booking_id/check-in
+func (h *BookingHandler) CheckIn(ctx *gin.Context) {
+	h.driverAction(ctx, h.service.CheckIn, "passenger checked in")
+}
+
+// POST /trips/:id/bookings/:booking_id/no-show
+func (h *BookingHandler) MarkNoShow(ctx *gin.Context) {
+	h.driverAction(ctx, h.service.MarkNoShow, "passenger marked as no-show")
+}
+
+func (h *BookingHandler) driverAction(ctx *gin.Context, action func(tripID, bookingID, driverID uint) error, done string) {
+
+	h.logger.Info("handler called",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+
+	tripID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid trip ID parameter"})
+		return
+	}
+
+	bookingID, err := strconv.ParseUint(ctx.Param("booking_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking ID parameter"})
+		return
+	}
+
+	var input dto.BookingDriverActionRequest
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	if err := action(uint(tripID), uint(bookingID), input.DriverID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip or booking not found"})
+		case errors.Is(err, services.ErrNotTripDriver):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrBookingNotInTrip):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrCheckInClosed),
+			errors.Is(err, services.ErrNoShowTooEarly),
+			errors.Is(err, services.ErrCannotCheckIn),
+			errors.Is(err, services.ErrCannotMarkNoShow):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("error processing driver action",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.Any("error", err.Error()),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info(done)
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
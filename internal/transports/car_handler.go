@@ -1,24 +1,28 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
-	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
 type CarHandler struct {
 	service services.CarService
+	cursors *pagination.Codec
 	logger  *slog.Logger
 }
 
-func NewCarHandler(service services.CarService, logger *slog.Logger) *CarHandler {
+func NewCarHandler(service services.CarService, cursors *pagination.Codec, logger *slog.Logger) *CarHandler {
 	return &CarHandler{
 		service: service,
+		cursors: cursors,
 		logger:  logger,
 	}
 }
@@ -53,6 +57,10 @@ func (h *CarHandler) Create(ctx *gin.Context) {
 
 	car, err := h.service.Create(uint(id), input)
 	if err != nil {
+		if status, ok := carErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to create car", slog.Uint64("owner_id", id), slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "car create error"})
 		return
@@ -65,23 +73,12 @@ func (h *CarHandler) Create(ctx *gin.Context) {
 // GET /cars
 func (h *CarHandler) List(ctx *gin.Context) {
 
-	var filter models.Page
-
-	if pageStr := ctx.Query("page"); pageStr != "" {
-		page, err := strconv.Atoi(pageStr)
-		if err == nil {
-			filter.Page = page
-		}
-	}
-
-	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
-		pageSize, err := strconv.Atoi(pageSizeStr)
-		if err == nil {
-			filter.PageSize = pageSize
-		}
+	filter, ok := queryPage(ctx, h.cursors, cursorScopeCars)
+	if !ok {
+		return
 	}
 
-	cars, err := h.service.List(filter)
+	cars, info, err := h.service.List(filter)
 	if err != nil {
 		h.logger.Error("Failed to list cars", slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cars"})
@@ -89,7 +86,7 @@ func (h *CarHandler) List(ctx *gin.Context) {
 	}
 
 	h.logger.Info("List of cars retrieved", slog.Int("count", len(cars)))
-	ctx.JSON(http.StatusOK, cars)
+	writePage(ctx, h.cursors, cursorScopeCars, cars, info)
 }
 
 // GET /cars/owner/:id
@@ -153,6 +150,10 @@ func (h *CarHandler) Update(ctx *gin.Context) {
 
 	car, err := h.service.Update(uint(id), input)
 	if err != nil {
+		if status, ok := carErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to update car", slog.Uint64("car_id", id), slog.String("error", err.Error()))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
 		return
@@ -181,3 +182,19 @@ func (h *CarHandler) Delete(ctx *gin.Context) {
 	h.logger.Info("Car deleted successfully", slog.Uint64("car_id", id))
 	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
+
+// carErrorStatus сопоставляет ошибки валидации автомобиля с HTTP-статусами
+func carErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrLicensePlateTaken):
+		return http.StatusConflict, true
+	case errors.Is(err, services.ErrInvalidLicensePlate),
+		errors.Is(err, services.ErrInvalidCarYear),
+		errors.Is(err, services.ErrInvalidCarBodyType),
+		errors.Is(err, services.ErrInvalidTrunkVolume):
+		return http.StatusBadRequest, true
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound, true
+	}
+	return 0, false
+}
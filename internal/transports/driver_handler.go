@@ -0,0 +1,109 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type DriverHandler struct {
+	service services.DriverService
+	logger  *slog.Logger
+}
+
+func NewDriverHandler(service services.DriverService, logger *slog.Logger) *DriverHandler {
+	return &DriverHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *DriverHandler) RegisterRoutes(ctx *gin.Engine) {
+	api := ctx.Group("/drivers")
+	{
+		api.GET("/:id/trips", h.Dashboard)
+		api.GET("/:id/trips/:trip_id/manifest", h.Manifest)
+		api.GET("/:id/profile", h.Profile)
+	}
+}
+
+// GET /drivers/:id/trips
+func (h *DriverHandler) Dashboard(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	dashboard, err := h.service.Dashboard(uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "driver not found"})
+			return
+		}
+		h.logger.Error("failed to build driver dashboard", slog.Uint64("driver_id", id), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dashboard)
+}
+
+// GET /drivers/:id/trips/:trip_id/manifest
+func (h *DriverHandler) Manifest(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	tripID, err := strconv.ParseUint(ctx.Param("trip_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid trip id"})
+		return
+	}
+
+	manifest, err := h.service.Manifest(uint(id), uint(tripID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
+			return
+		}
+		if errors.Is(err, services.ErrNotTripDriver) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to build manifest", slog.Uint64("trip_id", tripID), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, manifest)
+}
+
+// GET /drivers/:id/profile
+func (h *DriverHandler) Profile(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	profile, err := h.service.Profile(uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		h.logger.Error("failed to build driver profile", slog.Uint64("driver_id", id), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,72 @@
+package transports
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/storage"
+)
+
+// MediaHandler отдаёт файлы локального хранилища по подписанным ссылкам.
+// Для S3 не регистрируется — ссылки ведут напрямую в бакет.
+type MediaHandler struct {
+	storage *storage.LocalStorage
+	logger  *slog.Logger
+}
+
+func NewMediaHandler(storage *storage.LocalStorage, logger *slog.Logger) *MediaHandler {
+	return &MediaHandler{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (h *MediaHandler) RegisterRoutes(ctx *gin.Engine) {
+	ctx.GET("/media/*key", h.Get)
+}
+
+// GET /media/*key?expires=...&signature=...
+func (h *MediaHandler) Get(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	if err := h.storage.Verify(key, ctx.Query("expires"), ctx.Query("signature"), time.Now()); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "link is invalid or expired"})
+		return
+	}
+
+	file, err := h.storage.Get(ctx.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+			return
+		}
+		h.logger.Error("failed to read media", slog.String("key", key), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer file.Close()
+
+	// тип берём из содержимого: в хранилище лежат и фото, и документы
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		h.logger.Error("failed to read media", slog.String("key", key), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=600")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Content-Type", http.DetectContentType(head[:n]))
+	ctx.Status(http.StatusOK)
+
+	if _, err := io.Copy(ctx.Writer, io.MultiReader(bytes.NewReader(head[:n]), file)); err != nil {
+		h.logger.Warn("failed to stream media", slog.String("key", key), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,156 @@
+package transports
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+)
+
+// Scope курсоров: токен одного списка не принимается другим
+const (
+	cursorScopeUsers             = "users"
+	cursorScopeCars              = "cars"
+	cursorScopeTrips             = "trips"
+	cursorScopeBookings          = "bookings"
+	cursorScopeReviews           = "reviews"
+	cursorScopePassengerBookings = "passenger_bookings"
+	cursorScopeModeration        = "moderation"
+	cursorScopeVerifications     = "verifications"
+	cursorScopeAudit             = "audit"
+)
+
+const nextCursorHeader = "X-Next-Cursor"
+
+// queryPage читает page, pageSize и cursor из query; при ошибке сам отвечает 400 и возвращает false
+func queryPage(ctx *gin.Context, codec *pagination.Codec, scope string) (models.Page, bool) {
+	var page models.Page
+
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{
+		{"page", &page.Page},
+		{"pageSize", &page.PageSize},
+	} {
+		raw := ctx.Query(param.name)
+		if raw == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": param.name + " must be a positive integer"})
+			return models.Page{}, false
+		}
+
+		*param.dst = n
+	}
+
+	if token := ctx.Query("cursor"); token != "" {
+		cursor, err := codec.Decode(scope, token)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return models.Page{}, false
+		}
+
+		page.After = cursor
+	}
+
+	return page, true
+}
+
+// writePage отдаёт страницу в общем конверте и проставляет Link (RFC 8288) и X-Next-Cursor
+func writePage[T any](ctx *gin.Context, codec *pagination.Codec, scope string, items []T, info models.PageInfo) {
+	ctx.JSON(http.StatusOK, pageResponse(ctx, codec, scope, items, info))
+}
+
+// writeList отдаёт короткий список без пагинации (он ограничен по размеру) в том же конверте,
+// что и постраничные: одна страница со всеми записями
+func writeList[T any](ctx *gin.Context, items []T) {
+	if items == nil {
+		items = []T{}
+	}
+
+	ctx.JSON(http.StatusOK, dto.ListResponse[T]{
+		Items:    items,
+		Page:     1,
+		PageSize: len(items),
+		Total:    int64(len(items)),
+	})
+}
+
+// pageResponse проставляет заголовки пагинации и собирает конверт, не отправляя его:
+// нужен спискам, которые кладут в ответ что-то ещё
+func pageResponse[T any](ctx *gin.Context, codec *pagination.Codec, scope string, items []T, info models.PageInfo) dto.ListResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	resp := dto.ListResponse[T]{
+		Items:    items,
+		Page:     info.Page,
+		PageSize: info.PageSize,
+		Total:    info.Total,
+		HasNext:  info.HasNext(),
+	}
+
+	if info.Next != nil {
+		resp.NextCursor = codec.Encode(scope, *info.Next)
+		ctx.Header(nextCursorHeader, resp.NextCursor)
+	}
+
+	if links := pageLinks(ctx.Request.URL, info, resp.NextCursor); links != "" {
+		ctx.Header("Link", links)
+	}
+
+	return resp
+}
+
+// pageLinks строит ссылки first/prev/next/last, сохраняя остальные параметры запроса.
+// Клиент, который листает по курсору, получает next по курсору, а не по номеру страницы.
+func pageLinks(u *url.URL, info models.PageInfo, nextCursor string) string {
+	link := func(rel string, set map[string]string) string {
+		q := u.Query()
+		q.Del("page")
+		q.Del("cursor")
+		for k, v := range set {
+			q.Set(k, v)
+		}
+
+		target := url.URL{Path: u.Path, RawQuery: q.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+	}
+
+	var links []string
+
+	if info.Page == 0 {
+		links = append(links, link("first", nil))
+		if nextCursor != "" {
+			links = append(links, link("next", map[string]string{"cursor": nextCursor}))
+		}
+		return strings.Join(links, ", ")
+	}
+
+	last := 1
+	if info.PageSize > 0 && info.Total > 0 {
+		last = int((info.Total + int64(info.PageSize) - 1) / int64(info.PageSize))
+	}
+
+	links = append(links, link("first", map[string]string{"page": "1"}))
+	if info.Page > 1 {
+		links = append(links, link("prev", map[string]string{"page": strconv.Itoa(info.Page - 1)}))
+	}
+	if info.HasNext() {
+		links = append(links, link("next", map[string]string{"page": strconv.Itoa(info.Page + 1)}))
+	}
+	links = append(links, link("last", map[string]string{"page": strconv.Itoa(last)}))
+
+	return strings.Join(links, ", ")
+}
@@ -0,0 +1,191 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+// maxUploadBytes ограничивает тело multipart-запроса (сам файл + служебные части)
+const maxUploadBytes = 11 << 20
+
+type PhotoHandler struct {
+	service services.PhotoService
+	logger  *slog.Logger
+}
+
+func NewPhotoHandler(service services.PhotoService, logger *slog.Logger) *PhotoHandler {
+	return &PhotoHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *PhotoHandler) RegisterRoutes(ctx *gin.Engine) {
+	ctx.POST("/users/:id/avatar", h.UploadUserAvatar)
+	ctx.GET("/users/:id/avatar", h.GetUserAvatar)
+
+	ctx.POST("/cars/:id/photos", h.UploadCarPhoto)
+	ctx.GET("/cars/:id/photos", h.ListCarPhotos)
+	ctx.DELETE("/cars/:id/photos/:photo_id", h.DeleteCarPhoto)
+}
+
+// POST /users/:id/avatar (multipart, поле "file")
+func (h *PhotoHandler) UploadUserAvatar(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	file, ok := h.formFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.service.UploadUserAvatar(ctx.Request.Context(), uint(id), file)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, photo)
+}
+
+// GET /users/:id/avatar?size=thumb — редирект на подписанную ссылку
+func (h *PhotoHandler) GetUserAvatar(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	url, err := h.service.GetUserAvatarURL(ctx.Request.Context(), uint(id), ctx.Query("size") == "thumb")
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// POST /cars/:id/photos (multipart, поле "file")
+func (h *PhotoHandler) UploadCarPhoto(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	file, ok := h.formFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.service.UploadCarPhoto(ctx.Request.Context(), uint(id), file)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, photo)
+}
+
+// GET /cars/:id/photos
+func (h *PhotoHandler) ListCarPhotos(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	photos, err := h.service.ListCarPhotos(ctx.Request.Context(), uint(id))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writeList(ctx, photos)
+}
+
+// DELETE /cars/:id/photos/:photo_id
+func (h *PhotoHandler) DeleteCarPhoto(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	photoID, err := strconv.ParseUint(ctx.Param("photo_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid photo ID"})
+		return
+	}
+
+	if err := h.service.DeleteCarPhoto(ctx.Request.Context(), uint(id), uint(photoID)); err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *PhotoHandler) formFile(ctx *gin.Context) (multipart.File, bool) {
+	return formFile(ctx, h.logger, services.ErrImageTooLarge)
+}
+
+// formFile достаёт файл из поля "file" multipart-запроса; tooLarge — ошибка для слишком большого тела
+func formFile(ctx *gin.Context, logger *slog.Logger, tooLarge error) (multipart.File, bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUploadBytes)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge.Error()})
+			return nil, false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		logger.Error("failed to open uploaded file", slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+
+	return file, true
+}
+
+func (h *PhotoHandler) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, services.ErrAvatarNotSet):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImageTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedImage):
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidImageSize),
+		errors.Is(err, services.ErrImageDecodingFailed):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyCarPhotos):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("photo request failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -1,25 +1,30 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
 	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
 type ReviewHandler struct {
 	service services.ReviewService
+	cursors *pagination.Codec
 	logger  *slog.Logger
 }
 
-func NewReviewHandler(service services.ReviewService, logger *slog.Logger) *ReviewHandler {
+func NewReviewHandler(service services.ReviewService, cursors *pagination.Codec, logger *slog.Logger) *ReviewHandler {
 	return &ReviewHandler{
 		service: service,
+		cursors: cursors,
 		logger:  logger,
 	}
 }
@@ -32,7 +37,14 @@ func (h ReviewHandler) RegisterRoutes(ctx *gin.Engine) {
 		api.GET("/reviews/:id", h.GetByID)
 		api.PUT("/reviews/:id/:author_id", h.Update)
 		api.DELETE("/reviews/:id/:author_id", h.Delete)
+		api.POST("/reviews/:id/reports/:reporter_id", h.Report)
+		api.POST("/reviews/:id/reply/:author_id", h.Reply)
+		api.POST("/reviews/:id/helpful/:voter_id", h.MarkHelpful)
+		api.DELETE("/reviews/:id/helpful/:voter_id", h.UnmarkHelpful)
+		api.PUT("/reviews/:id/reply/:author_id", h.UpdateReply)
 	}
+
+	ctx.DELETE("/admin/reviews/:id", h.AdminDelete)
 }
 
 func (h *ReviewHandler) Create(ctx *gin.Context) {
@@ -80,8 +92,8 @@ func (h *ReviewHandler) Create(ctx *gin.Context) {
 			return
 		}
 
-		if err == services.ErrTripNotCompleted || err == services.ErrUserNotPassenger || err == services.ErrReviewAlreadyPresent {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if status, ok := reviewErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
 			return
 		}
 
@@ -101,22 +113,75 @@ func (h *ReviewHandler) Create(ctx *gin.Context) {
 
 }
 
+// GET /reviews?tripId=&driverId=&authorId=&minRating=&maxRating=&sort=
 func (h *ReviewHandler) List(ctx *gin.Context) {
+	var filter dto.ReviewFilter
+	var ok bool
 
-	var filter models.Page
+	if filter.TripID, ok = queryID(ctx, "tripId"); !ok {
+		return
+	}
+	if filter.DriverID, ok = queryID(ctx, "driverId"); !ok {
+		return
+	}
+	if filter.AuthorID, ok = queryID(ctx, "authorId"); !ok {
+		return
+	}
+	if filter.MinRating, ok = queryInt(ctx, "minRating"); !ok {
+		return
+	}
+	if filter.MaxRating, ok = queryInt(ctx, "maxRating"); !ok {
+		return
+	}
 
-	if err := ctx.ShouldBindQuery(&filter); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		h.logger.Error("invalid query parameters",
+	for _, r := range []*int{filter.MinRating, filter.MaxRating} {
+		if r != nil && (*r < 1 || *r > 5) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "rating filter must be between 1 and 5"})
+			return
+		}
+	}
+	if filter.MinRating != nil && filter.MaxRating != nil && *filter.MinRating > *filter.MaxRating {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minRating must not exceed maxRating"})
+		return
+	}
+
+	switch sort := ctx.Query("sort"); sort {
+	case "", dto.ReviewSortNewest, dto.ReviewSortHighest, dto.ReviewSortLowest, dto.ReviewSortHelpful:
+		filter.SortBy = sort
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "sort must be newest, highest, lowest or most_helpful"})
+		return
+	}
+
+	scope := reviewCursorScope(filter)
+
+	// у most_helpful курсоров нет: сортировка по живому счётчику, листается номерами страниц
+	if filter.SortBy == dto.ReviewSortHelpful && ctx.Query("cursor") != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "cursor is not supported for sort=most_helpful, use page"})
+		return
+	}
+
+	page, ok := queryPage(ctx, h.cursors, scope)
+	if !ok {
+		return
+	}
+
+	filter.Page, filter.PageSize, filter.After = page.Page, page.PageSize, page.After
+
+	reviews, info, err := h.service.List(filter)
+	if err != nil {
+		h.logger.Error("error listing reviews",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
 			slog.Any("error", err),
 		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
-	reviews, err := h.service.List(filter)
+
+	summary, err := h.service.Summary(filter)
 	if err != nil {
-		h.logger.Error("error listing reviews",
+		h.logger.Error("error building review summary",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
 			slog.Any("error", err),
@@ -124,11 +189,90 @@ func (h *ReviewHandler) List(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
+
 	h.logger.Info("reviews listed successfully",
 		slog.String("method", ctx.Request.Method),
 		slog.String("path", ctx.FullPath()),
 	)
-	ctx.JSON(http.StatusOK, reviews)
+	ctx.JSON(http.StatusOK, dto.ReviewListResponse{
+		ListResponse: pageResponse(ctx, h.cursors, scope, reviews, info),
+		Summary:      *summary,
+	})
+}
+
+// reviewCursorScope привязывает курсор к сортировке и всем фильтрам: курсор, выданный
+// для другой выборки, не пройдёт проверку подписи
+func reviewCursorScope(filter dto.ReviewFilter) string {
+	id := func(v *uint) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	}
+	num := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+
+	return strings.Join([]string{
+		cursorScopeReviews,
+		filter.SortBy,
+		id(filter.TripID),
+		id(filter.DriverID),
+		id(filter.AuthorID),
+		num(filter.MinRating),
+		num(filter.MaxRating),
+	}, ":")
+}
+
+// POST /reviews/:id/helpful/:voter_id
+func (h *ReviewHandler) MarkHelpful(ctx *gin.Context) {
+	h.setHelpful(ctx, true)
+}
+
+// DELETE /reviews/:id/helpful/:voter_id
+func (h *ReviewHandler) UnmarkHelpful(ctx *gin.Context) {
+	h.setHelpful(ctx, false)
+}
+
+func (h *ReviewHandler) setHelpful(ctx *gin.Context, helpful bool) {
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+
+	voterID, err := strconv.ParseUint(ctx.Param("voter_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid voter id"})
+		return
+	}
+
+	count, err := h.service.SetHelpful(uint(id), uint(voterID), helpful)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+			return
+		}
+
+		if status, ok := reviewErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error("error saving helpful vote",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ReviewHelpfulResponse{ReviewID: uint(id), HelpfulCount: count})
 }
 
 func (h *ReviewHandler) GetByID(ctx *gin.Context) {
@@ -143,7 +287,12 @@ func (h *ReviewHandler) GetByID(ctx *gin.Context) {
 		)
 		return
 	}
-	review, err := h.service.GetByID(uint(id))
+	var viewerID *uint
+	if p := principal(ctx); p != nil {
+		viewerID = &p.UserID
+	}
+
+	review, err := h.service.GetByID(uint(id), viewerID)
 	if err != nil {
 		if err == repository.ErrNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
@@ -238,3 +387,181 @@ func (h *ReviewHandler) Delete(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
+
+// DELETE /admin/reviews/:id
+func (h *ReviewHandler) AdminDelete(ctx *gin.Context) {
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+
+	if err := h.service.DeleteAsAdmin(uint(id)); err != nil {
+		if err == repository.ErrNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+			return
+		}
+		h.logger.Error("failed to delete review", slog.Uint64("review_id", id), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *ReviewHandler) Report(ctx *gin.Context) {
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+
+	reporterID, err := strconv.ParseUint(ctx.Param("reporter_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid reporter id"})
+		return
+	}
+
+	var req dto.ReviewReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Report(uint(id), uint(reporterID), req.Reason)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+			return
+		}
+
+		if status, ok := reviewErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error("error reporting review",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("review reported",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+	ctx.JSON(http.StatusCreated, report)
+}
+
+// POST /reviews/:id/reply/:author_id
+func (h *ReviewHandler) Reply(ctx *gin.Context) {
+	h.saveReply(ctx, http.StatusCreated, h.service.Reply)
+}
+
+// PUT /reviews/:id/reply/:author_id
+func (h *ReviewHandler) UpdateReply(ctx *gin.Context) {
+	h.saveReply(ctx, http.StatusOK, h.service.UpdateReply)
+}
+
+func (h *ReviewHandler) saveReply(
+	ctx *gin.Context,
+	okStatus int,
+	save func(reviewID, authorID uint, text string) (*models.ReviewReply, error),
+) {
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+
+	authorID, err := strconv.ParseUint(ctx.Param("author_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid author id"})
+		return
+	}
+
+	var req dto.ReviewReplyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reply, err := save(uint(id), uint(authorID), req.Text)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+			return
+		}
+
+		if status, ok := reviewErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		h.logger.Error("error saving review reply",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("review reply saved",
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.FullPath()),
+	)
+	ctx.JSON(okStatus, reply)
+}
+
+// reviewErrorStatus: 403 — автор не имеет права оценивать, 409 — отзыв уже есть,
+// 422 — поездка или запрос не подходят для отзыва
+func reviewErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrUserNotPassenger),
+		errors.Is(err, services.ErrBookingNotApproved),
+		errors.Is(err, services.ErrPassengerNoShow),
+		errors.Is(err, services.ErrPassengerNotCheckedIn),
+		errors.Is(err, services.ErrCannotReportOwnReview),
+		errors.Is(err, services.ErrReplyNotAllowed),
+		errors.Is(err, services.ErrCannotVoteOwnReview):
+		return http.StatusForbidden, true
+	case errors.Is(err, services.ErrReviewAlreadyPresent),
+		errors.Is(err, services.ErrReviewAlreadyReported),
+		errors.Is(err, services.ErrInvalidModerationState),
+		errors.Is(err, services.ErrReplyAlreadyExists):
+		return http.StatusConflict, true
+	case errors.Is(err, services.ErrTripNotCompleted),
+		errors.Is(err, services.ErrReviewWindowClosed),
+		errors.Is(err, services.ErrReviewTargetRequired),
+		errors.Is(err, services.ErrInvalidReviewTarget),
+		errors.Is(err, services.ErrModerationReasonNeeded),
+		errors.Is(err, services.ErrReplyEditWindowClosed),
+		errors.Is(err, services.ErrRatingRequired),
+		errors.Is(err, services.ErrSubRatingsNotAllowed):
+		return http.StatusUnprocessableEntity, true
+	}
+	return 0, false
+}
+
+// queryID читает необязательный идентификатор из query; при ошибке сам отвечает 400
+func queryID(ctx *gin.Context, key string) (*uint, bool) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+
+	v, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key})
+		return nil, false
+	}
+
+	id := uint(v)
+	return &id, true
+}
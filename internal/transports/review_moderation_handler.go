@@ -0,0 +1,251 @@
+package transports
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+// ReviewModerationHandler — админские ручки очереди модерации отзывов
+type ReviewModerationHandler struct {
+	service services.ReviewService
+	cursors *pagination.Codec
+	logger  *slog.Logger
+}
+
+func NewReviewModerationHandler(service services.ReviewService, cursors *pagination.Codec, logger *slog.Logger) *ReviewModerationHandler {
+	return &ReviewModerationHandler{
+		service: service,
+		cursors: cursors,
+		logger:  logger,
+	}
+}
+
+func (h *ReviewModerationHandler) RegisterRoutes(ctx *gin.Engine) {
+	api := ctx.Group("/admin/reviews")
+
+	api.GET("", h.Queue)
+	api.GET("/:id/reports", h.Reports)
+	api.POST("/:id/approve", h.moderate(services.ModerationApprove))
+	api.POST("/:id/hide", h.moderate(services.ModerationHide))
+	api.POST("/:id/restore", h.moderate(services.ModerationRestore))
+
+	replies := ctx.Group("/admin/review-replies")
+
+	replies.GET("", h.ReplyQueue)
+	replies.POST("/:id/approve", h.moderateReply(services.ModerationApprove))
+	replies.POST("/:id/hide", h.moderateReply(services.ModerationHide))
+	replies.POST("/:id/restore", h.moderateReply(services.ModerationRestore))
+}
+
+// GET /admin/reviews?status=pending_moderation
+func (h *ReviewModerationHandler) Queue(ctx *gin.Context) {
+
+	status, ok := queryModerationStatus(ctx)
+	if !ok {
+		return
+	}
+
+	scope := cursorScopeModeration + ":" + string(status)
+
+	filter, ok := queryPage(ctx, h.cursors, scope)
+	if !ok {
+		return
+	}
+
+	reviews, info, err := h.service.ListForModeration(status, filter)
+	if err != nil {
+		h.logger.Error("error listing moderation queue",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	items := make([]dto.ModeratedReview, len(reviews))
+	for i, review := range reviews {
+		items[i] = dto.NewModeratedReview(review)
+	}
+
+	writePage(ctx, h.cursors, scope, items, info)
+}
+
+// GET /admin/reviews/:id/reports
+func (h *ReviewModerationHandler) Reports(ctx *gin.Context) {
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+
+	reports, err := h.service.ListReports(uint(id))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+			return
+		}
+		h.logger.Error("error listing review reports",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	writeList(ctx, reports)
+}
+
+// POST /admin/reviews/:id/{approve,hide,restore}
+func (h *ReviewModerationHandler) moderate(action services.ModerationAction) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+			return
+		}
+
+		var req dto.ReviewModerationRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !actingAs(ctx, req.ModeratorID) {
+			return
+		}
+
+		review, err := h.service.Moderate(uint(id), action, req.ModeratorID, req.Reason)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+				return
+			}
+
+			if status, ok := reviewErrorStatus(err); ok {
+				ctx.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+
+			h.logger.Error("error moderating review",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.String("action", string(action)),
+				slog.Any("error", err),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		h.logger.Info("review moderated",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.String("action", string(action)),
+		)
+		ctx.JSON(http.StatusOK, dto.NewModeratedReview(*review))
+	}
+}
+
+// GET /admin/review-replies?status=pending_moderation
+func (h *ReviewModerationHandler) ReplyQueue(ctx *gin.Context) {
+
+	status, ok := queryModerationStatus(ctx)
+	if !ok {
+		return
+	}
+
+	scope := cursorScopeModeration + ":replies:" + string(status)
+
+	filter, ok := queryPage(ctx, h.cursors, scope)
+	if !ok {
+		return
+	}
+
+	replies, info, err := h.service.ListRepliesForModeration(status, filter)
+	if err != nil {
+		h.logger.Error("error listing reply moderation queue",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	items := make([]dto.ModeratedReply, len(replies))
+	for i, reply := range replies {
+		items[i] = dto.NewModeratedReply(reply)
+	}
+
+	writePage(ctx, h.cursors, scope, items, info)
+}
+
+// POST /admin/review-replies/:id/{approve,hide,restore}
+func (h *ReviewModerationHandler) moderateReply(action services.ModerationAction) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply id"})
+			return
+		}
+
+		var req dto.ReviewModerationRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !actingAs(ctx, req.ModeratorID) {
+			return
+		}
+
+		reply, err := h.service.ModerateReply(uint(id), action, req.ModeratorID, req.Reason)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "reply not found"})
+				return
+			}
+
+			if status, ok := reviewErrorStatus(err); ok {
+				ctx.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+
+			h.logger.Error("error moderating review reply",
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.FullPath()),
+				slog.String("action", string(action)),
+				slog.Any("error", err),
+			)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.NewModeratedReply(*reply))
+	}
+}
+
+// queryModerationStatus читает ?status=, по умолчанию — очередь на модерацию
+func queryModerationStatus(ctx *gin.Context) (constants.ReviewStatus, bool) {
+	status := constants.ReviewStatus(ctx.DefaultQuery("status", string(constants.ReviewPendingModeration)))
+
+	switch status {
+	case constants.ReviewPendingModeration, constants.ReviewPublished, constants.ReviewHidden:
+		return status, true
+	}
+
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+	return "", false
+}
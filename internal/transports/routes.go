@@ -4,7 +4,11 @@ import (
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/policy"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
+	"github.com/mutsaevz/team-5-ambitious/internal/storage"
 )
 
 func RegisterRoutes(
@@ -15,16 +19,172 @@ func RegisterRoutes(
 	tripService services.TripService,
 	bookingService services.BookingService,
 	reviewService services.ReviewService,
+	photoService services.PhotoService,
+	driverService services.DriverService,
+	savedSearchService services.SavedSearchService,
+	verificationService services.VerificationService,
+	blockService services.BlockService,
+	authService services.AuthService,
+	auditService services.AuditService,
+	accountService services.AccountService,
+	accessPolicy *policy.Policy,
+	blobStorage storage.BlobStorage,
+	cursors *pagination.Codec,
 ) {
-	userHandler := NewUserHandler(userService, logger)
-	carHandler := NewCarHandler(carService, logger)
-	tripHandler := NewTripHandler(tripService, logger)
-	bookingHandler := NewBookingHandler(bookingService, logger)
-	reviewHandler := NewReviewHandler(reviewService, logger)
+	access := &accessControl{
+		auth:   authService,
+		policy: accessPolicy,
+		audit:  auditService,
+		logger: logger,
+	}
+	rules := accessRules(access)
+
+	// проверка доступа должна стоять до регистрации ручек, иначе они её не получат
+	routes.Use(access.middleware(rules))
+
+	userHandler := NewUserHandler(userService, cursors, logger)
+	carHandler := NewCarHandler(carService, cursors, logger)
+	tripHandler := NewTripHandler(tripService, cursors, logger)
+	bookingHandler := NewBookingHandler(bookingService, cursors, logger)
+	reviewHandler := NewReviewHandler(reviewService, cursors, logger)
+	photoHandler := NewPhotoHandler(photoService, logger)
+	driverHandler := NewDriverHandler(driverService, logger)
+	savedSearchHandler := NewSavedSearchHandler(savedSearchService, logger)
 
 	userHandler.RegisterRoutes(routes)
 	carHandler.RegisterRoutes(routes)
 	tripHandler.RegisterRoutes(routes)
 	bookingHandler.RegisterRoutes(routes)
 	reviewHandler.RegisterRoutes(routes)
+	NewReviewModerationHandler(reviewService, cursors, logger).RegisterRoutes(routes)
+	photoHandler.RegisterRoutes(routes)
+	driverHandler.RegisterRoutes(routes)
+	savedSearchHandler.RegisterRoutes(routes)
+	NewVerificationHandler(verificationService, cursors, logger).RegisterRoutes(routes)
+	NewBlockHandler(blockService, logger).RegisterRoutes(routes)
+	NewAuthHandler(authService, userService, logger).RegisterRoutes(routes)
+	NewAdminHandler(userService, authService, auditService, cursors, logger).RegisterRoutes(routes)
+	NewAccountHandler(accountService, logger).RegisterRoutes(routes)
+
+	if local, ok := blobStorage.(*storage.LocalStorage); ok {
+		NewMediaHandler(local, logger).RegisterRoutes(routes)
+	}
+
+	checkAccessRules(routes.Routes(), rules)
+}
+
+// accessRules — кто может вызывать каждую ручку. Ключ — метод и шаблон пути, как в gin.
+func accessRules(a *accessControl) map[string]accessRule {
+	driverRole := roles(constants.RoleDriver, constants.RoleAdmin)
+
+	return map[string]accessRule{
+		// вход и регистрация
+		"POST /auth/login":      public,
+		"POST /auth/refresh":    public,
+		"POST /auth/logout":     authenticated,
+		"POST /auth/logout-all": authenticated,
+		"GET /auth/me":          authenticated,
+		"POST /users/":          public,
+
+		// пользователи
+		"GET /users/:id":                              public,
+		"GET /users/:id/profile":                      public,
+		"PATCH /users/:id":                            self("id"),
+		"DELETE /users/:id":                           self("id"),
+		"PUT /users/:id/password":                     self("id"),
+		"GET /users/:id/sessions":                     self("id"),
+		"DELETE /users/:id/sessions/:session_id":      self("id"),
+		"GET /users/:id/avatar":                       public,
+		"POST /users/:id/avatar":                      self("id"),
+		"GET /users/:id/bookings":                     selfOrStaff("id"),
+		"GET /users/:id/documents":                    selfOrStaff("id"),
+		"POST /users/:id/documents":                   self("id"),
+		"GET /users/:id/saved-searches":               self("id"),
+		"POST /users/:id/saved-searches":              self("id"),
+		"DELETE /users/:id/saved-searches/:search_id": self("id"),
+		"GET /users/:id/blocks":                       self("id"),
+		"POST /users/:id/blocks/:blocked_id":          self("id"),
+		"DELETE /users/:id/blocks/:blocked_id":        self("id"),
+		"POST /users/:id/exports":                     self("id"),
+		"GET /users/:id/exports":                      self("id"),
+		"GET /users/:id/exports/:export_id":           self("id"),
+
+		// автомобили
+		"GET /cars/":                        public,
+		"GET /cars/:id":                     public,
+		"GET /cars/owner/:id":               public,
+		"POST /cars/:id":                    self("id"),
+		"PUT /cars/:id":                     a.carOwner("id"),
+		"DELETE /cars/:id":                  a.carOwner("id"),
+		"GET /cars/:id/photos":              public,
+		"POST /cars/:id/photos":             a.carOwner("id"),
+		"DELETE /cars/:id/photos/:photo_id": a.carOwner("id"),
+
+		// поездки
+		"GET /trips/":                  public,
+		"GET /trips/:id":               public,
+		"POST /trips/driver/:driverID": allOf(self("driverID"), driverRole),
+		"PUT /trips/:id":               a.tripDriver("id"),
+		"DELETE /trips/:id":            a.tripDriver("id"),
+		"POST /trips/:id/start":        a.tripDriver("id"),
+		"POST /trips/:id/finish":       a.tripDriver("id"),
+
+		"POST /trips/:id/bookings/:booking_id/check-in": a.tripDriver("id"),
+		"POST /trips/:id/bookings/:booking_id/no-show":  a.tripDriver("id"),
+
+		// кабинет водителя
+		"GET /drivers/:id/trips":                   self("id"),
+		"GET /drivers/:id/trips/:trip_id/manifest": self("id"),
+		"GET /drivers/:id/profile":                 public,
+
+		// заявки
+		"POST /bookings/":           authenticated,
+		"GET /bookings/:id":         a.bookingViewer("id"),
+		"PATCH /bookings/:id":       a.bookingDriver("id"),
+		"POST /bookings/:id/cancel": a.bookingPassenger("id"),
+		"GET /bookings/driver/:driver_id/trip/:trip_id/pending": self("driver_id"),
+
+		// отзывы
+		"GET /reviews":                           public,
+		"GET /reviews/:id":                       public,
+		"POST /trips/:id/:author_id/reviews":     self("author_id"),
+		"PUT /reviews/:id/:author_id":            self("author_id"),
+		"DELETE /reviews/:id/:author_id":         self("author_id"),
+		"POST /reviews/:id/reports/:reporter_id": self("reporter_id"),
+		"POST /reviews/:id/reply/:author_id":     self("author_id"),
+		"PUT /reviews/:id/reply/:author_id":      self("author_id"),
+		"POST /reviews/:id/helpful/:voter_id":    self("voter_id"),
+		"DELETE /reviews/:id/helpful/:voter_id":  self("voter_id"),
+
+		// медиафайлы локального хранилища
+		"GET /media/*key": public,
+
+		// модерация и проверка документов — поддержка и администраторы
+		"GET /admin/reviews":                     staffOnly,
+		"GET /admin/reviews/:id/reports":         staffOnly,
+		"POST /admin/reviews/:id/approve":        staffOnly,
+		"POST /admin/reviews/:id/hide":           staffOnly,
+		"POST /admin/reviews/:id/restore":        staffOnly,
+		"GET /admin/review-replies":              staffOnly,
+		"POST /admin/review-replies/:id/approve": staffOnly,
+		"POST /admin/review-replies/:id/hide":    staffOnly,
+		"POST /admin/review-replies/:id/restore": staffOnly,
+		"GET /admin/verifications":               staffOnly,
+		"GET /admin/verifications/:id/file":      staffOnly,
+		"POST /admin/verifications/:id/approve":  staffOnly,
+		"POST /admin/verifications/:id/reject":   staffOnly,
+		"GET /admin/users":                       staffOnly,
+		"GET /admin/users/:id":                   staffOnly,
+		"GET /admin/bookings":                    staffOnly,
+
+		// необратимые действия — только администраторы
+		"PUT /admin/users/:id/role":     adminOnly,
+		"PUT /admin/users/:id/password": adminOnly,
+		"DELETE /admin/users/:id":       adminOnly,
+		"DELETE /admin/trips/:id":       adminOnly,
+		"PATCH /admin/bookings/:id":     adminOnly,
+		"DELETE /admin/bookings/:id":    adminOnly,
+		"DELETE /admin/reviews/:id":     adminOnly,
+		"GET /admin/audit":              adminOnly,
+	}
 }
@@ -0,0 +1,115 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+type SavedSearchHandler struct {
+	service services.SavedSearchService
+	logger  *slog.Logger
+}
+
+func NewSavedSearchHandler(service services.SavedSearchService, logger *slog.Logger) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *SavedSearchHandler) RegisterRoutes(ctx *gin.Engine) {
+	api := ctx.Group("/users/:id/saved-searches")
+	{
+		api.POST("", h.Create)
+		api.GET("", h.List)
+		api.DELETE("/:search_id", h.Delete)
+	}
+}
+
+// POST /users/:id/saved-searches
+func (h *SavedSearchHandler) Create(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.SavedSearchCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := h.service.Create(uint(userID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrInvalidSearchWindow):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTooManySavedSearches):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to save search", slog.Uint64("user_id", userID), slog.Any("error", err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, search)
+}
+
+// GET /users/:id/saved-searches
+func (h *SavedSearchHandler) List(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	searches, err := h.service.ListByUser(uint(userID))
+	if err != nil {
+		h.logger.Error("failed to list saved searches", slog.Uint64("user_id", userID), slog.Any("error", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	writeList(ctx, searches)
+}
+
+// DELETE /users/:id/saved-searches/:search_id
+func (h *SavedSearchHandler) Delete(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	searchID, err := strconv.ParseUint(ctx.Param("search_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid search id"})
+		return
+	}
+
+	if err := h.service.Delete(uint(userID), uint(searchID)); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "saved search not found"})
+		case errors.Is(err, services.ErrNotSearchOwner):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to delete saved search", slog.Uint64("search_id", searchID), slog.Any("error", err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
@@ -1,25 +1,32 @@
 package transports
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
 	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
 type TripHandler struct {
 	service services.TripService
+	cursors *pagination.Codec
 	logger  *slog.Logger
 }
 
-func NewTripHandler(service services.TripService, logger *slog.Logger) *TripHandler {
+func NewTripHandler(service services.TripService, cursors *pagination.Codec, logger *slog.Logger) *TripHandler {
 	return &TripHandler{
 		service: service,
+		cursors: cursors,
 		logger:  logger,
 	}
 }
@@ -32,7 +39,11 @@ func (h *TripHandler) RegisterRoutes(ctx *gin.Engine) {
 		api.GET("/:id", h.GetByID)
 		api.PUT("/:id", h.Update)
 		api.DELETE("/:id", h.Delete)
+		api.POST("/:id/start", h.Start)
+		api.POST("/:id/finish", h.Finish)
 	}
+
+	ctx.DELETE("/admin/trips/:id", h.Delete)
 }
 
 func (h *TripHandler) Create(ctx *gin.Context) {
@@ -56,6 +67,10 @@ func (h *TripHandler) Create(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "driver not found"})
 			return
 		}
+		if errors.Is(err, services.ErrDriverNotVerified) || errors.Is(err, services.ErrCarNotVerified) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to create trip", slog.Any("error", err))
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
@@ -84,25 +99,95 @@ func (h *TripHandler) List(ctx *gin.Context) {
 		filter.StartTime = &time
 	}
 
-	if pageStr := ctx.Query("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil {
-			filter.Page = page
+	if color := ctx.Query("carColor"); color != "" {
+		filter.CarColor = &color
+	}
+
+	if bodyStr := ctx.Query("bodyType"); bodyStr != "" {
+		bodyType := constants.CarBodyType(bodyStr)
+		if !bodyType.IsValid() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid body type"})
+			return
 		}
+		filter.CarBodyType = &bodyType
 	}
 
-	if pagesizeStr := ctx.Query("pageSize"); pagesizeStr != "" {
-		if pageSize, err := strconv.Atoi(pagesizeStr); err == nil {
-			filter.PageSize = pageSize
+	var ok bool
+
+	if filter.MinCarYear, ok = queryInt(ctx, "minCarYear"); !ok {
+		return
+	}
+	if filter.MinTrunkCapacity, ok = queryInt(ctx, "minTrunkCapacity"); !ok {
+		return
+	}
+	if filter.AirConditioning, ok = queryBool(ctx, "airConditioning"); !ok {
+		return
+	}
+	if filter.ChildSeat, ok = queryBool(ctx, "childSeat"); !ok {
+		return
+	}
+	if filter.WheelchairAccess, ok = queryBool(ctx, "wheelchairAccess"); !ok {
+		return
+	}
+	if filter.SmokingAllowed, ok = queryBool(ctx, "smoking"); !ok {
+		return
+	}
+	if filter.PetsAllowed, ok = queryBool(ctx, "pets"); !ok {
+		return
+	}
+	if filter.MusicAllowed, ok = queryBool(ctx, "music"); !ok {
+		return
+	}
+	if filter.BigLuggage, ok = queryBool(ctx, "bigLuggage"); !ok {
+		return
+	}
+	if filter.WomenOnly, ok = queryBool(ctx, "womenOnly"); !ok {
+		return
+	}
+
+	if minRating := ctx.Query("minDriverRating"); minRating != "" {
+		v, err := strconv.ParseFloat(minRating, 64)
+		if err != nil || v < 0 || v > 5 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "minDriverRating must be between 0 and 5"})
+			return
 		}
+		filter.MinDriverRating = &v
+	}
+
+	if filter.ViewerID, ok = queryID(ctx, "viewerId"); !ok {
+		return
+	}
+
+	// вошедшему пользователю не показываем поездки тех, с кем он в блокировке
+	if p := principal(ctx); p != nil {
+		filter.ViewerID = &p.UserID
+	}
+
+	switch sort := ctx.Query("sort"); sort {
+	case "", dto.TripSortStartTime, dto.TripSortDriverRating:
+		filter.SortBy = sort
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "sort must be start_time or driver_rating"})
+		return
+	}
+
+	// курсоры разных сортировок несовместимы между собой
+	scope := cursorScopeTrips + ":" + filter.SortBy
+
+	page, ok := queryPage(ctx, h.cursors, scope)
+	if !ok {
+		return
 	}
 
-	list, err := h.service.List(filter)
+	filter.Page, filter.PageSize, filter.After = page.Page, page.PageSize, page.After
+
+	list, info, err := h.service.List(filter)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, list)
+	writePage(ctx, h.cursors, scope, list, info)
 }
 
 func (h *TripHandler) GetByID(ctx *gin.Context) {
@@ -175,3 +260,78 @@ func (h *TripHandler) Delete(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
+
+// POST /trips/:id/start
+func (h *TripHandler) Start(ctx *gin.Context) {
+	h.driverTransition(ctx, h.service.Start)
+}
+
+// POST /trips/:id/finish
+func (h *TripHandler) Finish(ctx *gin.Context) {
+	h.driverTransition(ctx, h.service.Finish)
+}
+
+func (h *TripHandler) driverTransition(ctx *gin.Context, transition func(ctx context.Context, tripID, driverID uint) (*models.Trip, error)) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req dto.TripDriverActionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	trip, err := transition(ctx.Request.Context(), uint(id), req.DriverID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "trip not found"})
+		case errors.Is(err, services.ErrNotTripDriver):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTripCannotStart), errors.Is(err, services.ErrTripNotInProgress):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to change trip status", slog.Uint64("trip_id", id), slog.Any("error", err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trip)
+}
+
+// queryInt читает необязательный целочисленный query-параметр; при ошибке сам отвечает 400
+func queryInt(ctx *gin.Context, key string) (*int, bool) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key})
+		return nil, false
+	}
+
+	return &v, true
+}
+
+// queryBool читает необязательный булев query-параметр; при ошибке сам отвечает 400
+func queryBool(ctx *gin.Context, key string) (*bool, bool) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return nil, true
+	}
+
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key})
+		return nil, false
+	}
+
+	return &v, true
+}
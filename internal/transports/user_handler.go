@@ -1,24 +1,30 @@
 package transports
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mutsaevz/team-5-ambitious/internal/dto"
-	"github.com/mutsaevz/team-5-ambitious/internal/models"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/policy"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
 	"github.com/mutsaevz/team-5-ambitious/internal/services"
 )
 
 type UserHandler struct {
 	service services.UserService
+	cursors *pagination.Codec
 	logger  *slog.Logger
 }
 
-func NewUserHandler(service services.UserService, logger *slog.Logger) *UserHandler {
+func NewUserHandler(service services.UserService, cursors *pagination.Codec, logger *slog.Logger) *UserHandler {
 	return &UserHandler{
 		service: service,
+		cursors: cursors,
 		logger:  logger,
 	}
 }
@@ -27,10 +33,15 @@ func (h UserHandler) RegisterRoutes(ctx *gin.Engine) {
 	api := ctx.Group("/users")
 	{
 		api.POST("/", h.Create)
-		api.GET("/", h.List)
 		api.GET("/:id", h.GetByID)
+		api.GET("/:id/profile", h.PublicProfile)
 		api.PATCH("/:id", h.Update)
-		api.DELETE("/:id", h.Delete)
+	}
+
+	admin := ctx.Group("/admin/users")
+	{
+		admin.GET("", h.List)
+		admin.GET("/:id", h.GetByID)
 	}
 }
 
@@ -54,6 +65,11 @@ func (h *UserHandler) Create(ctx *gin.Context) {
 
 	user, err := h.service.Create(&input)
 	if err != nil {
+		if status, ok := userErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
 		h.logger.Error("error adding user",
 			slog.String("method", ctx.Request.Method),
 			slog.String("path", ctx.FullPath()),
@@ -74,23 +90,12 @@ func (h *UserHandler) List(ctx *gin.Context) {
 		slog.String("path", ctx.FullPath()),
 	)
 
-	var filter models.Page
-
-	if pageStr := ctx.Query("page"); pageStr != "" {
-		page, err := strconv.Atoi(pageStr)
-		if err == nil {
-			filter.Page = page
-		}
-	}
-
-	if pageSizeStr := ctx.Query("pageSize"); pageSizeStr != "" {
-		pageSize, err := strconv.Atoi(pageSizeStr)
-		if err == nil {
-			filter.PageSize = pageSize
-		}
+	filter, ok := queryPage(ctx, h.cursors, cursorScopeUsers)
+	if !ok {
+		return
 	}
 
-	users, err := h.service.List(filter)
+	users, info, err := h.service.List(filter)
 	if err != nil {
 		h.logger.Error("user list error",
 			slog.String("method", ctx.Request.Method),
@@ -101,7 +106,7 @@ func (h *UserHandler) List(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, users)
+	writePage(ctx, h.cursors, cursorScopeUsers, users, info)
 }
 
 func (h *UserHandler) GetByID(ctx *gin.Context) {
@@ -124,18 +129,53 @@ func (h *UserHandler) GetByID(ctx *gin.Context) {
 
 	user, err := h.service.GetByID(uint(id))
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
 		h.logger.Error("handler called",
 			slog.String("method", ctx.Request.Method),
 			slog.String("error", "user not found"),
 			slog.Any("error", err),
 		)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// телефон и баланс видят только сам пользователь и сотрудники, остальным — публичная карточка
+	if p := principal(ctx); p == nil || policy.SelfOrStaff(p, user.ID) != nil {
+		ctx.JSON(http.StatusOK, dto.NewPublicProfile(user, time.Now()))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, user)
 }
 
+// GET /users/:id/profile
+func (h *UserHandler) PublicProfile(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	profile, err := h.service.PublicProfile(uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		h.logger.Error("error loading public profile",
+			slog.Uint64("user_id", id),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
 func (h *UserHandler) Update(ctx *gin.Context) {
 	h.logger.Info("handler called",
 		slog.String("method", ctx.Request.Method),
@@ -169,6 +209,11 @@ func (h *UserHandler) Update(ctx *gin.Context) {
 	updated, err := h.service.Update(uint(id), input)
 
 	if err != nil {
+		if status, ok := userErrorStatus(err); ok {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
 		h.logger.Error("error saving changes",
 			slog.Uint64("user_id", uint64(id)),
 			slog.Any("error", err),
@@ -181,34 +226,23 @@ func (h *UserHandler) Update(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, updated)
 }
 
-func (h *UserHandler) Delete(ctx *gin.Context) {
-	h.logger.Info("handler called",
-		slog.String("method", ctx.Request.Method),
-		slog.String("path", ctx.FullPath()),
-	)
-
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid id",
-			slog.String("method", ctx.Request.Method),
-			slog.String("path", ctx.FullPath()),
-			slog.Any("error", err),
-		)
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
-		return
+// userErrorStatus сопоставляет ошибки проверки пользователя с HTTP-статусами
+func userErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrPhoneTaken):
+		return http.StatusConflict, true
+	case errors.Is(err, services.ErrInvalidGender),
+		errors.Is(err, services.ErrInvalidPhone),
+		errors.Is(err, services.ErrBioTooLong),
+		errors.Is(err, services.ErrInvalidBirthYear),
+		errors.Is(err, services.ErrInvalidLanguage),
+		errors.Is(err, services.ErrInvalidContactMethod),
+		errors.Is(err, services.ErrInvalidRole):
+		return http.StatusBadRequest, true
+	case errors.Is(err, services.ErrCannotChangeOwnRole):
+		return http.StatusForbidden, true
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound, true
 	}
-
-	if err := h.service.Delete(uint(id)); err != nil {
-		h.logger.Error("failed to delete user",
-			slog.String("method", ctx.Request.Method),
-			slog.Uint64("user_id", uint64(id)),
-			slog.Any("error", err),
-		)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete user"})
-		return
-	}
-
-	h.logger.Info("user deleted successfully")
-	ctx.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+	return 0, false
 }
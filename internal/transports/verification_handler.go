@@ -0,0 +1,206 @@
+package transports
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mutsaevz/team-5-ambitious/internal/constants"
+	"github.com/mutsaevz/team-5-ambitious/internal/dto"
+	"github.com/mutsaevz/team-5-ambitious/internal/pagination"
+	"github.com/mutsaevz/team-5-ambitious/internal/repository"
+	"github.com/mutsaevz/team-5-ambitious/internal/services"
+)
+
+// VerificationHandler — загрузка документов водителем и очередь их проверки для администратора
+type VerificationHandler struct {
+	service services.VerificationService
+	cursors *pagination.Codec
+	logger  *slog.Logger
+}
+
+func NewVerificationHandler(service services.VerificationService, cursors *pagination.Codec, logger *slog.Logger) *VerificationHandler {
+	return &VerificationHandler{
+		service: service,
+		cursors: cursors,
+		logger:  logger,
+	}
+}
+
+func (h *VerificationHandler) RegisterRoutes(ctx *gin.Engine) {
+	ctx.POST("/users/:id/documents", h.Submit)
+	ctx.GET("/users/:id/documents", h.ListByUser)
+
+	admin := ctx.Group("/admin/verifications")
+
+	admin.GET("", h.Queue)
+	admin.GET("/:id/file", h.File)
+	admin.POST("/:id/approve", h.Approve)
+	admin.POST("/:id/reject", h.Reject)
+}
+
+// POST /users/:id/documents (multipart: поле "file" и тип документа в поле "type")
+func (h *VerificationHandler) Submit(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	file, ok := formFile(ctx, h.logger, services.ErrDocumentTooLarge)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	docType := constants.DocumentType(ctx.PostForm("type"))
+
+	doc, err := h.service.Submit(ctx.Request.Context(), uint(id), docType, file)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, doc)
+}
+
+// GET /users/:id/documents
+func (h *VerificationHandler) ListByUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	docs, err := h.service.ListByUser(uint(id))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writeList(ctx, docs)
+}
+
+// GET /admin/verifications?status=pending
+func (h *VerificationHandler) Queue(ctx *gin.Context) {
+	status := constants.VerificationStatus(ctx.DefaultQuery("status", string(constants.VerificationPending)))
+
+	switch status {
+	case constants.VerificationPending, constants.VerificationApproved, constants.VerificationRejected:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	scope := cursorScopeVerifications + ":" + string(status)
+
+	filter, ok := queryPage(ctx, h.cursors, scope)
+	if !ok {
+		return
+	}
+
+	docs, info, err := h.service.ListForReview(status, filter)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	writePage(ctx, h.cursors, scope, docs, info)
+}
+
+// GET /admin/verifications/:id/file — редирект на подписанную ссылку
+func (h *VerificationHandler) File(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid document id"})
+		return
+	}
+
+	url, err := h.service.DocumentURL(ctx.Request.Context(), uint(id))
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// POST /admin/verifications/:id/approve
+func (h *VerificationHandler) Approve(ctx *gin.Context) {
+	id, req, ok := h.decision(ctx)
+	if !ok {
+		return
+	}
+
+	doc, err := h.service.Approve(id, req.ReviewerID)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, doc)
+}
+
+// POST /admin/verifications/:id/reject
+func (h *VerificationHandler) Reject(ctx *gin.Context) {
+	id, req, ok := h.decision(ctx)
+	if !ok {
+		return
+	}
+
+	doc, err := h.service.Reject(id, req.ReviewerID, req.Reason)
+	if err != nil {
+		h.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, doc)
+}
+
+func (h *VerificationHandler) decision(ctx *gin.Context) (uint, dto.VerificationDecisionRequest, bool) {
+	var req dto.VerificationDecisionRequest
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid document id"})
+		return 0, req, false
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, req, false
+	}
+
+	if !actingAs(ctx, req.ReviewerID) {
+		return 0, req, false
+	}
+
+	return uint(id), req, true
+}
+
+func (h *VerificationHandler) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDocumentTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedDocument):
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDocumentType),
+		errors.Is(err, services.ErrRejectReasonNeeded):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrVerificationPending),
+		errors.Is(err, services.ErrDocumentAlreadyReviewed),
+		errors.Is(err, services.ErrDocumentOutdated):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("verification request failed",
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.FullPath()),
+			slog.Any("error", err),
+		)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}